@@ -0,0 +1,81 @@
+// Command pixelbin-gen generates a Go package with typed constructors for the operations
+// of the Pixelbin module catalogue.
+//
+// The catalogue is read from a JSON snapshot of GetModules, or fetched with an API token:
+//
+//	pixelbin-gen -snapshot modules.json -package operations -out operations/operations.go
+//	PIXELBIN_API_TOKEN=... pixelbin-gen -out operations/operations.go -save modules.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/codegen"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+func main() {
+	snapshot := flag.String("snapshot", "", "JSON snapshot of GetModules to read instead of fetching the catalogue")
+	token := flag.String("token", os.Getenv("PIXELBIN_API_TOKEN"), "API token used to fetch the catalogue, defaults to $PIXELBIN_API_TOKEN")
+	domain := flag.String("domain", "https://api.pixelbin.io", "API domain used to fetch the catalogue")
+	save := flag.String("save", "", "file to save the fetched catalogue to, for use with -snapshot")
+	packageName := flag.String("package", codegen.DefaultPackage, "name of the generated package")
+	out := flag.String("out", "", "file to write the generated source to, defaults to stdout")
+	flag.Parse()
+
+	if err := run(*snapshot, *token, *domain, *save, *packageName, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "pixelbin-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(snapshot, token, domain, save, packageName, out string) error {
+	data, err := catalogueJSON(snapshot, token, domain)
+	if err != nil {
+		return err
+	}
+	if save != "" {
+		if err := os.WriteFile(save, data, 0o644); err != nil {
+			return err
+		}
+	}
+	catalogue, err := url.ParseCatalogue(data)
+	if err != nil {
+		return err
+	}
+	src, err := codegen.Generate(catalogue, packageName)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
+
+// catalogueJSON reads the snapshot, or fetches the catalogue when there is none
+func catalogueJSON(snapshot, token, domain string) ([]byte, error) {
+	if snapshot != "" {
+		return os.ReadFile(snapshot)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("either -snapshot or -token is required")
+	}
+	config := platform.NewPixelbinConfig(token, domain)
+	config.SetOAuthClient()
+	pixelbin := platform.NewPixelbinClient(config)
+	modules, err := pixelbin.Assets.GetModules(platform.GetModulesXQuery{})
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(modules, "", "  ")
+}
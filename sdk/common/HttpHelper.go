@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"strings"
 )
 
 func HttpRequest(method string, apiUrl string, queryParams map[string]string, data interface{}, headers map[string]string) ([]byte, error) {
@@ -55,6 +56,11 @@ func HttpRequest(method string, apiUrl string, queryParams map[string]string, da
 	}
 	//Setting headers
 	for k, v := range headers {
+		// the signed host header must not be sent twice alongside the one net/http writes
+		if strings.EqualFold(k, "host") {
+			req.Host = v
+			continue
+		}
 		req.Header[k] = []string{v}
 	}
 	//Setting query params
@@ -73,6 +79,28 @@ func HttpRequest(method string, apiUrl string, queryParams map[string]string, da
 	return resData, nil
 }
 
+// HttpStream performs a request and hands back the open response so that the body can be streamed.
+// Any status outside 2xx and 304 is returned as an FDKError and the body is closed.
+func HttpStream(method string, rawUrl string, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(method, rawUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	client := http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if (res.StatusCode < 200 || res.StatusCode > 299) && res.StatusCode != http.StatusNotModified {
+		defer res.Body.Close()
+		return nil, NewFDKError(fmt.Sprintf("request to %s failed with status %d", rawUrl, res.StatusCode)).SetStatus(res.StatusCode)
+	}
+	return res, nil
+}
+
 func processHTTPResponse(res *http.Response) ([]byte, error) {
 	var errResp *FDKError
 	data, err := ioutil.ReadAll(res.Body)
@@ -123,6 +151,15 @@ func CreateMultiPartFormPayload(file *os.File, reqBodyMap map[string]interface{}
 						return nil, "", err
 					}
 				}
+			} else if object, ok := val.(map[string]interface{}); ok {
+				// objects such as metadata are sent as JSON
+				data, err := json.Marshal(object)
+				if err != nil {
+					return nil, "", err
+				}
+				if err := writer.WriteField(key, string(data)); err != nil {
+					return nil, "", err
+				}
 			} else {
 				err = writer.WriteField(key, fmt.Sprintf("%v", val))
 				if err != nil {
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/cache"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
 	"os"
+	"path/filepath"
 )
 
 // PixelbinClient holds all the PixelbinConfig object properties
@@ -27,12 +30,18 @@ func NewPixelbinClient(config *PixelbinConfig) *PixelbinClient {
 
 // Assets holds Assets object properties
 type Assets struct {
-	config *PixelbinConfig
+	config        *PixelbinConfig
+	downloadCache *cache.DiskCache
+	uploadPolicy  *UploadPolicy
+	catalogue     catalogueCache
+	presetCache   *url.PresetCache
 }
 
 // NewAssets returns new Assets instance
 func NewAssets(config *PixelbinConfig) *Assets {
-	return &Assets{config: config}
+	assets := &Assets{config: config}
+	assets.presetCache = url.NewPresetCache(assets.fetchPreset)
+	return assets
 }
 
 type AddCredentialsXQuery struct {
@@ -854,6 +863,9 @@ func (c *Assets) AddPreset(
 	p AddPresetXQuery,
 ) (map[string]interface{}, error) {
 
+	// the preset may have changed even when the request fails
+	defer c.presetCache.Invalidate(p.PresetName)
+
 	type body struct {
 		PresetName string `json:"presetName,omitempty"`
 
@@ -948,6 +960,9 @@ func (c *Assets) UpdatePreset(
 	p UpdatePresetXQuery,
 ) (map[string]interface{}, error) {
 
+	// the preset may have changed even when the request fails
+	defer c.presetCache.Invalidate(p.PresetName)
+
 	type body struct {
 		Archived bool `json:"archived,omitempty"`
 	}
@@ -995,6 +1010,9 @@ func (c *Assets) DeletePreset(
 	p DeletePresetXQuery,
 ) (map[string]interface{}, error) {
 
+	// the preset may have changed even when the request fails
+	defer c.presetCache.Invalidate(p.PresetName)
+
 	queryParams := make(map[string]string)
 
 	apiClient := &APIClient{
@@ -1067,6 +1085,9 @@ type FileUploadXQuery struct {
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
 	Overwrite        bool                   `json:"overwrite,omitempty"`
 	FilenameOverride bool                   `json:"filenameOverride,omitempty"`
+	Collision        CollisionStrategyEnum  `json:"-"`
+	// skipPolicy uploads as is, for internal uploads of existing assets
+	skipPolicy bool
 }
 
 /*
@@ -1080,6 +1101,24 @@ func (c *Assets) FileUpload(
 	p FileUploadXQuery,
 ) (map[string]interface{}, error) {
 
+	fileName := ""
+	if p.File != nil {
+		fileName = filepath.Base(p.File.Name())
+	}
+	err := c.applyUploadPolicy(policyUpload{path: &p.Path, name: &p.Name, access: &p.Access, tags: &p.Tags, metadata: &p.Metadata, source: fileName, fileName: fileName, file: p.File, skip: p.skipPolicy})
+	if err != nil {
+		return nil, err
+	}
+	name, format := uploadName(p.Name, fileName)
+	plan, err := c.planCollision(p.Collision, p.Overwrite, p.FilenameOverride, p.Path, name, format, false)
+	if err != nil {
+		return nil, err
+	}
+	if plan.existing != nil {
+		return plan.existing, nil
+	}
+	p.Overwrite, p.FilenameOverride = plan.overwrite, plan.filenameOverride
+
 	type body struct {
 		File *os.File `json:"file,omitempty"`
 
@@ -1129,7 +1168,7 @@ func (c *Assets) FileUpload(
 
 	response, err := apiClient.Execute()
 	if err != nil {
-		return nil, err
+		return nil, c.uploadFailed(plan, err)
 	}
 	resp := map[string]interface{}{}
 	err = json.Unmarshal(response, &resp)
@@ -1149,6 +1188,9 @@ type UrlUploadXQuery struct {
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
 	Overwrite        bool                   `json:"overwrite,omitempty"`
 	FilenameOverride bool                   `json:"filenameOverride,omitempty"`
+	Collision        CollisionStrategyEnum  `json:"-"`
+	// skipPolicy uploads as is, for internal uploads of existing assets
+	skipPolicy bool
 }
 
 /*
@@ -1162,6 +1204,20 @@ func (c *Assets) UrlUpload(
 	p UrlUploadXQuery,
 ) (map[string]interface{}, error) {
 
+	err := c.applyUploadPolicy(policyUpload{path: &p.Path, name: &p.Name, access: &p.Access, tags: &p.Tags, metadata: &p.Metadata, source: p.URL, fileName: urlFileName(p.URL), url: p.URL, skip: p.skipPolicy})
+	if err != nil {
+		return nil, err
+	}
+	name, format := uploadName(p.Name, urlFileName(p.URL))
+	plan, err := c.planCollision(p.Collision, p.Overwrite, p.FilenameOverride, p.Path, name, format, false)
+	if err != nil {
+		return nil, err
+	}
+	if plan.existing != nil {
+		return plan.existing, nil
+	}
+	p.Overwrite, p.FilenameOverride = plan.overwrite, plan.filenameOverride
+
 	type body struct {
 		URL string `json:"url,omitempty"`
 
@@ -1211,7 +1267,7 @@ func (c *Assets) UrlUpload(
 
 	response, err := apiClient.Execute()
 	if err != nil {
-		return nil, err
+		return nil, c.uploadFailed(plan, err)
 	}
 	resp := map[string]interface{}{}
 	err = json.Unmarshal(response, &resp)
@@ -1231,6 +1287,7 @@ type CreateSignedUrlXQuery struct {
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
 	Overwrite        bool                   `json:"overwrite,omitempty"`
 	FilenameOverride bool                   `json:"filenameOverride,omitempty"`
+	Collision        CollisionStrategyEnum  `json:"-"`
 }
 
 /*
@@ -1245,6 +1302,21 @@ func (c *Assets) CreateSignedUrl(
 	p CreateSignedUrlXQuery,
 ) (map[string]interface{}, error) {
 
+	fileName := signedUploadFileName(p.Name, p.Format)
+	err := c.applyUploadPolicy(policyUpload{path: &p.Path, name: &p.Name, access: &p.Access, tags: &p.Tags, metadata: &p.Metadata, source: fileName, fileName: fileName})
+	if err != nil {
+		return nil, err
+	}
+	name, format := p.Name, p.Format
+	plan, err := c.planCollision(p.Collision, p.Overwrite, p.FilenameOverride, p.Path, name, format, true)
+	if err != nil {
+		return nil, err
+	}
+	if plan.existing != nil {
+		return plan.existing, nil
+	}
+	p.Overwrite, p.FilenameOverride = plan.overwrite, plan.filenameOverride
+
 	type body struct {
 		Name string `json:"name,omitempty"`
 
@@ -1314,6 +1386,7 @@ type CreateSignedUrlV2XQuery struct {
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
 	Overwrite        bool                   `json:"overwrite,omitempty"`
 	FilenameOverride bool                   `json:"filenameOverride,omitempty"`
+	Collision        CollisionStrategyEnum  `json:"-"`
 	Expiry           float64                `json:"expiry,omitempty"`
 }
 
@@ -1328,6 +1401,21 @@ func (c *Assets) CreateSignedUrlV2(
 	p CreateSignedUrlV2XQuery,
 ) (map[string]interface{}, error) {
 
+	fileName := signedUploadFileName(p.Name, p.Format)
+	err := c.applyUploadPolicy(policyUpload{path: &p.Path, name: &p.Name, access: &p.Access, tags: &p.Tags, metadata: &p.Metadata, source: fileName, fileName: fileName})
+	if err != nil {
+		return nil, err
+	}
+	name, format := p.Name, p.Format
+	plan, err := c.planCollision(p.Collision, p.Overwrite, p.FilenameOverride, p.Path, name, format, true)
+	if err != nil {
+		return nil, err
+	}
+	if plan.existing != nil {
+		return plan.existing, nil
+	}
+	p.Overwrite, p.FilenameOverride = plan.overwrite, plan.filenameOverride
+
 	type body struct {
 		Name string `json:"name,omitempty"`
 
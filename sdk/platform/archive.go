@@ -0,0 +1,210 @@
+package platform
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+// ArchivedAtMetadataKey is the metadata key holding the time a file was archived. It is
+// namespaced so that it does not clash with the keys of the metadata of the file.
+const ArchivedAtMetadataKey = "pixelbin:archivedAt"
+
+type ArchiveXQuery struct {
+	// Source is the fileId of a file or the path of a folder
+	Source string
+	// Concurrency bounds the number of files updated at the same time
+	Concurrency int
+}
+
+/*
+summary: Archive a file or folder
+
+description: Soft delete a file, or a folder with everything below it, by setting isActive to false.
+The archive time is kept in the metadata of every file under ArchivedAtMetadataKey,
+pixelbin:archivedAt, so that PurgeArchived can delete files archived for long enough.
+Unarchive removes the key again.
+
+params: ArchiveXQuery
+*/
+func (c *Assets) Archive(
+	p ArchiveXQuery,
+) (*ArchiveResponse, error) {
+
+	return c.setArchived(p, true)
+}
+
+/*
+summary: Unarchive a file or folder
+
+description: Restore a file, or a folder with everything below it, archived with Archive.
+
+params: ArchiveXQuery
+*/
+func (c *Assets) Unarchive(
+	p ArchiveXQuery,
+) (*ArchiveResponse, error) {
+
+	return c.setArchived(p, false)
+}
+
+func (c *Assets) setArchived(p ArchiveXQuery, archived bool) (*ArchiveResponse, error) {
+	tree, err := c.resolveTree(p.Source)
+	if err != nil {
+		return nil, err
+	}
+	archivedAt := time.Now().UTC().Format(time.RFC3339)
+	result := &ArchiveResponse{Failures: []ArchiveFailure{}}
+	var mu sync.Mutex
+	forEachConcurrently(len(tree.files), p.Concurrency, func(i int) {
+		item := tree.files[i]
+		changed, err := c.setFileArchived(item.FileId, archived, archivedAt)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			result.Failures = append(result.Failures, ArchiveFailure{ID: item.ID, Path: item.FileId, Error: err.Error()})
+		} else if changed {
+			result.Files++
+		}
+	})
+
+	folders := tree.folders
+	if tree.folder != nil {
+		folders = append([]ExploreItem{*tree.folder}, folders...)
+	}
+	for _, folder := range folders {
+		if _, err := c.patchFolder(folder.ID, map[string]interface{}{"isActive": !archived}); err != nil {
+			result.Failures = append(result.Failures, ArchiveFailure{ID: folder.ID, Path: path.Join(folder.Path, folder.Name), Error: err.Error()})
+			continue
+		}
+		result.Folders++
+	}
+	return result, nil
+}
+
+// setFileArchived archives or restores a single file, reporting whether it had to change
+func (c *Assets) setFileArchived(fileId string, archived bool, archivedAt string) (bool, error) {
+	file, err := c.getFile(fileId)
+	if err != nil {
+		return false, err
+	}
+	if file.IsActive != archived {
+		return false, nil
+	}
+	metadata := map[string]interface{}{}
+	for key, value := range file.Metadata {
+		metadata[key] = value
+	}
+	if archived {
+		metadata[ArchivedAtMetadataKey] = archivedAt
+	} else {
+		delete(metadata, ArchivedAtMetadataKey)
+	}
+	_, err = c.patchFile(fileId, map[string]interface{}{"isActive": !archived, "metadata": metadata})
+	return err == nil, err
+}
+
+type ListArchivedXQuery struct {
+	// Path of the folder to search, empty for the whole organization
+	Path string
+	// Concurrency bounds the number of files fetched at the same time
+	Concurrency int
+}
+
+/*
+summary: List archived files
+
+description: Walk the asset tree below Path and return every file that is not active.
+ArchivedAt is empty for files deactivated without Archive.
+
+params: ListArchivedXQuery
+*/
+func (c *Assets) ListArchived(
+	p ListArchivedXQuery,
+) ([]ArchivedFile, error) {
+
+	files, err := c.fileDetails(p.Path, p.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	archived := []ArchivedFile{}
+	for _, file := range files {
+		if file.IsActive {
+			continue
+		}
+		archivedAt, _ := file.Metadata[ArchivedAtMetadataKey].(string)
+		archived = append(archived, ArchivedFile{ID: file.ID, FileId: file.FileId, Size: file.Size, ArchivedAt: archivedAt})
+	}
+	return archived, nil
+}
+
+type PurgeArchivedXQuery struct {
+	// Path of the folder to sweep, empty for the whole organization
+	Path string
+	// OlderThanDays is the number of days a file has to be archived for before it is purged
+	OlderThanDays int
+	// DryRun lists the files that would be purged without deleting them
+	DryRun      bool
+	BatchSize   int
+	Concurrency int
+}
+
+/*
+summary: Purge archived files
+
+description: Permanently delete the files archived with Archive more than OlderThanDays days ago.
+Files deactivated without Archive have no archive time and are never purged.
+
+params: PurgeArchivedXQuery
+*/
+func (c *Assets) PurgeArchived(
+	p PurgeArchivedXQuery,
+) (*PurgeArchivedResponse, error) {
+
+	if p.OlderThanDays < 0 {
+		return nil, common.NewFDKError("OlderThanDays cannot be negative")
+	}
+	archived, err := c.ListArchived(ListArchivedXQuery{Path: p.Path, Concurrency: p.Concurrency})
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().Add(-time.Duration(p.OlderThanDays) * 24 * time.Hour)
+	expired := []ArchivedFile{}
+	for _, file := range archived {
+		archivedAt, err := time.Parse(time.RFC3339, file.ArchivedAt)
+		if err == nil && !archivedAt.After(cutoff) {
+			expired = append(expired, file)
+		}
+	}
+
+	result := &PurgeArchivedResponse{DryRun: p.DryRun, Purged: expired, Failures: []DeleteFailure{}}
+	if p.DryRun {
+		return result, nil
+	}
+	items := make([]ExploreItem, len(expired))
+	for i, file := range expired {
+		items[i] = ExploreItem{ID: file.ID, FileId: file.FileId}
+	}
+	batches := splitBatches(items, p.BatchSize)
+	var mu sync.Mutex
+	forEachConcurrently(len(batches), p.Concurrency, func(i int) {
+		_, failures := c.deleteBatch(batches[i])
+		mu.Lock()
+		defer mu.Unlock()
+		result.Failures = append(result.Failures, failures...)
+	})
+
+	failed := map[string]bool{}
+	for _, failure := range result.Failures {
+		failed[failure.ID] = true
+	}
+	result.Purged = []ArchivedFile{}
+	for _, file := range expired {
+		if !failed[file.ID] {
+			result.Purged = append(result.Purged, file)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,487 @@
+package platform
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+const (
+	backupManifestName  = "manifest.json"
+	backupAssetsDir     = "assets/"
+	backupSidecarSuffix = ".pixelbin.json"
+)
+
+type BackupXQuery struct {
+	// Path of the folder to back up, empty for the whole organization
+	Path string
+	// Destination is a directory, or a file when Format is TAR
+	Destination string
+	Format      BackupFormatEnum
+	// Incremental skips downloading assets that are unchanged since the previous
+	// backup in Destination. It only applies to the DIRECTORY format.
+	Incremental bool
+	// SignToken and SignTokenID are used to download private assets
+	SignTokenID int
+	SignToken   string
+	Progress    func(BackupProgress)
+}
+
+/*
+summary: Back up assets
+
+description: Walk the asset tree below Path and write every original along with a
+JSON sidecar holding its path, name, tags, metadata and access into a directory or tar archive.
+Failures of individual assets are reported in the response and do not stop the backup.
+
+params: BackupXQuery
+*/
+func (c *Assets) Backup(
+	p BackupXQuery,
+) (*BackupResponse, error) {
+
+	if p.Destination == "" {
+		return nil, common.NewFDKError("Destination is required to back up assets")
+	}
+	format := p.Format
+	if format == "" {
+		format = DIRECTORY
+	}
+	if err := format.IsValid(); err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	archive, err := openBackupArchive(p.Destination, format)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := BackupManifest{
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Path:      path.Join(p.Path),
+		Folders:   []string{},
+		Assets:    []string{},
+	}
+	result := &BackupResponse{Failures: []BackupFailure{}}
+	err = c.Walk(WalkXQuery{Path: p.Path}, func(item ExploreItem) error {
+		if item.Type == "folder" {
+			manifest.Folders = append(manifest.Folders, path.Join(item.Path, item.Name))
+			result.Folders++
+			return nil
+		}
+		skipped, written, err := c.backupAsset(item, archive, p)
+		if err != nil {
+			result.Failures = append(result.Failures, BackupFailure{FileId: item.FileId, Error: err.Error()})
+		} else {
+			manifest.Assets = append(manifest.Assets, item.FileId)
+			result.Assets++
+			result.Bytes += written
+			if skipped {
+				result.Skipped++
+			}
+		}
+		if p.Progress != nil {
+			p.Progress(BackupProgress{FileId: item.FileId, Processed: result.Assets + len(result.Failures), Skipped: skipped, Bytes: result.Bytes})
+		}
+		return nil
+	})
+	if err != nil {
+		archive.close()
+		return result, err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		archive.close()
+		return result, common.NewFDKError(err.Error())
+	}
+	if err := archive.writeFile(backupManifestName, bytes.NewReader(data), int64(len(data))); err != nil {
+		archive.close()
+		return result, err
+	}
+	return result, archive.close()
+}
+
+// backupAsset writes the original and sidecar of a single file into the archive
+func (c *Assets) backupAsset(item ExploreItem, archive backupArchive, p BackupXQuery) (bool, int64, error) {
+	file, err := c.getFile(item.FileId)
+	if err != nil {
+		return false, 0, err
+	}
+	asset := BackupAsset{
+		ID:       file.ID,
+		FileId:   file.FileId,
+		Path:     file.Path,
+		Name:     file.Name,
+		Format:   file.Format,
+		Size:     file.Size,
+		Access:   file.Access,
+		Tags:     file.Tags,
+		Metadata: file.Metadata,
+	}
+	contentName := backupAssetsDir + file.FileId
+	sidecarName := contentName + backupSidecarSuffix
+
+	if p.Incremental {
+		if previous, ok := readBackupSidecar(archive, sidecarName); ok && previous.ID == file.ID && previous.Size == file.Size && previous.Checksum != "" {
+			if size, ok := archive.size(contentName); ok && float64(size) == file.Size {
+				asset.Checksum = previous.Checksum
+				return true, 0, writeBackupSidecar(archive, sidecarName, asset)
+			}
+		}
+	}
+
+	tmp, err := os.CreateTemp("", "pixelbin-backup-*")
+	if err != nil {
+		return false, 0, common.NewFDKError(err.Error())
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	downloaded, err := c.Download(DownloadXQuery{
+		File:        file,
+		Writer:      io.MultiWriter(tmp, hasher),
+		SignTokenID: p.SignTokenID,
+		SignToken:   p.SignToken,
+		SkipCache:   true,
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return false, 0, common.NewFDKError(err.Error())
+	}
+	if err := archive.writeFile(contentName, tmp, downloaded.BytesWritten); err != nil {
+		return false, 0, err
+	}
+	asset.Checksum = hex.EncodeToString(hasher.Sum(nil))
+	return false, downloaded.BytesWritten, writeBackupSidecar(archive, sidecarName, asset)
+}
+
+type RestoreXQuery struct {
+	// Source is a directory or tar file written by Backup
+	Source string
+	// Path of the folder to restore into, empty to restore to the original location
+	Path      string
+	Overwrite bool
+	// Incremental skips assets that already exist with the same size
+	Incremental bool
+	Progress    func(BackupProgress)
+}
+
+/*
+summary: Restore assets from a backup
+
+description: Re-create the folders and upload the files of a backup written by Backup,
+with the same tags, metadata and access. Failures of individual assets are reported in the
+response and do not stop the restore.
+
+params: RestoreXQuery
+*/
+func (c *Assets) Restore(
+	p RestoreXQuery,
+) (*BackupResponse, error) {
+
+	root, cleanup, err := openBackupSource(p.Source)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	data, err := os.ReadFile(filepath.Join(root, backupManifestName))
+	if err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	var manifest BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+
+	result := &BackupResponse{Failures: []BackupFailure{}}
+	if p.Path == "" {
+		// restore into the original location
+		p.Path = manifest.Path
+	}
+	if _, err := c.EnsureFolder(EnsureFolderXQuery{Path: p.Path}); err != nil {
+		return result, err
+	}
+	for _, folder := range manifest.Folders {
+		if _, err := c.EnsureFolder(EnsureFolderXQuery{Path: path.Rebase(folder, manifest.Path, p.Path)}); err != nil {
+			return result, err
+		}
+		result.Folders++
+	}
+
+	for _, fileId := range manifest.Assets {
+		skipped, written, err := c.restoreAsset(root, fileId, manifest.Path, p)
+		if err != nil {
+			result.Failures = append(result.Failures, BackupFailure{FileId: fileId, Error: err.Error()})
+		} else {
+			result.Assets++
+			result.Bytes += written
+			if skipped {
+				result.Skipped++
+			}
+		}
+		if p.Progress != nil {
+			p.Progress(BackupProgress{FileId: fileId, Processed: result.Assets + len(result.Failures), Skipped: skipped, Bytes: result.Bytes})
+		}
+	}
+	return result, nil
+}
+
+// restoreAsset uploads a single file of a backup
+func (c *Assets) restoreAsset(root, fileId, basePath string, p RestoreXQuery) (bool, int64, error) {
+	contentPath, err := (&directoryArchive{root: root}).localPath(backupAssetsDir + fileId)
+	if err != nil {
+		return false, 0, err
+	}
+	data, err := os.ReadFile(contentPath + backupSidecarSuffix)
+	if err != nil {
+		return false, 0, common.NewFDKError(err.Error())
+	}
+	var asset BackupAsset
+	if err := json.Unmarshal(data, &asset); err != nil {
+		return false, 0, common.NewFDKError(err.Error())
+	}
+	targetPath := path.Rebase(asset.Path, basePath, p.Path)
+
+	if p.Incremental {
+		existing, err := c.getFile(path.Rebase(asset.FileId, basePath, p.Path))
+		if err == nil && existing.ID != "" && existing.Size == asset.Size {
+			return true, 0, nil
+		}
+	}
+
+	file, err := os.Open(contentPath)
+	if err != nil {
+		return false, 0, common.NewFDKError(err.Error())
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return false, 0, common.NewFDKError(err.Error())
+	}
+	if asset.Checksum != "" {
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, file); err != nil {
+			return false, 0, common.NewFDKError(err.Error())
+		}
+		if sum := hex.EncodeToString(hasher.Sum(nil)); sum != asset.Checksum {
+			return false, 0, common.NewFDKError(fmt.Sprintf("checksum mismatch: expected %s, got %s", asset.Checksum, sum))
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return false, 0, common.NewFDKError(err.Error())
+		}
+	}
+	_, err = c.FileUpload(FileUploadXQuery{
+		File:       file,
+		Path:       targetPath,
+		Name:       asset.Name,
+		Access:     asset.Access,
+		Tags:       asset.Tags,
+		Metadata:   asset.Metadata,
+		Overwrite:  p.Overwrite,
+		skipPolicy: true,
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return false, info.Size(), nil
+}
+
+// backupArchive stores the entries of a backup
+type backupArchive interface {
+	size(name string) (int64, bool)
+	readFile(name string) ([]byte, error)
+	writeFile(name string, src io.Reader, size int64) error
+	close() error
+}
+
+func openBackupArchive(destination string, format BackupFormatEnum) (backupArchive, error) {
+	if format == TAR {
+		file, err := os.Create(destination)
+		if err != nil {
+			return nil, common.NewFDKError(err.Error())
+		}
+		return &tarArchive{file: file, writer: tar.NewWriter(file)}, nil
+	}
+	if err := os.MkdirAll(destination, 0o755); err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	return &directoryArchive{root: destination}, nil
+}
+
+// openBackupSource returns a directory holding the backup, extracting tar archives to a temporary one
+func openBackupSource(source string) (string, func(), error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", nil, common.NewFDKError(err.Error())
+	}
+	if info.IsDir() {
+		return source, func() {}, nil
+	}
+	root, err := os.MkdirTemp("", "pixelbin-restore-*")
+	if err != nil {
+		return "", nil, common.NewFDKError(err.Error())
+	}
+	cleanup := func() { os.RemoveAll(root) }
+	if err := extractTar(source, root); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return root, cleanup, nil
+}
+
+func extractTar(source, root string) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	defer file.Close()
+	archive := &directoryArchive{root: root}
+	reader := tar.NewReader(file)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return common.NewFDKError(err.Error())
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := archive.writeFile(header.Name, reader, header.Size); err != nil {
+			return err
+		}
+	}
+}
+
+func readBackupSidecar(archive backupArchive, name string) (BackupAsset, bool) {
+	var asset BackupAsset
+	data, err := archive.readFile(name)
+	if err != nil {
+		return asset, false
+	}
+	if err := json.Unmarshal(data, &asset); err != nil {
+		return asset, false
+	}
+	return asset, true
+}
+
+func writeBackupSidecar(archive backupArchive, name string, asset BackupAsset) error {
+	data, err := json.MarshalIndent(asset, "", "    ")
+	if err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	return archive.writeFile(name, bytes.NewReader(data), int64(len(data)))
+}
+
+// directoryArchive writes a backup as plain files below root
+type directoryArchive struct {
+	root string
+}
+
+func (d *directoryArchive) localPath(name string) (string, error) {
+	local := filepath.Join(d.root, filepath.FromSlash(name))
+	if !strings.HasPrefix(local, filepath.Clean(d.root)+string(os.PathSeparator)) {
+		return "", common.NewFDKError(fmt.Sprintf("invalid backup entry %s", name))
+	}
+	return local, nil
+}
+
+func (d *directoryArchive) size(name string) (int64, bool) {
+	local, err := d.localPath(name)
+	if err != nil {
+		return 0, false
+	}
+	info, err := os.Stat(local)
+	if err != nil {
+		return 0, false
+	}
+	return info.Size(), true
+}
+
+func (d *directoryArchive) readFile(name string) ([]byte, error) {
+	local, err := d.localPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(local)
+}
+
+func (d *directoryArchive) writeFile(name string, src io.Reader, size int64) error {
+	local, err := d.localPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(local), 0o755); err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	file, err := os.Create(local)
+	if err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	defer file.Close()
+	if _, err := io.CopyN(file, src, size); err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	return nil
+}
+
+func (d *directoryArchive) close() error {
+	return nil
+}
+
+// tarArchive writes a backup as a single tar file
+type tarArchive struct {
+	file   *os.File
+	writer *tar.Writer
+}
+
+func (t *tarArchive) size(name string) (int64, bool) {
+	return 0, false
+}
+
+func (t *tarArchive) readFile(name string) ([]byte, error) {
+	return nil, os.ErrNotExist
+}
+
+func (t *tarArchive) writeFile(name string, src io.Reader, size int64) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    size,
+		ModTime: time.Now(),
+	}
+	if err := t.writer.WriteHeader(header); err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	if _, err := io.CopyN(t.writer, src, size); err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	return nil
+}
+
+func (t *tarArchive) close() error {
+	if err := t.writer.Close(); err != nil {
+		t.file.Close()
+		return common.NewFDKError(err.Error())
+	}
+	if err := t.file.Close(); err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	return nil
+}
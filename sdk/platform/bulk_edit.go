@@ -0,0 +1,141 @@
+package platform
+
+import (
+	"reflect"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+)
+
+type BulkEditXQuery struct {
+	// Query selects the files to edit, Path is used when Query is nil
+	Query *ListFilesXQuery
+	// Path of a folder whose files are edited recursively, empty for the root folder
+	Path string
+
+	AddTags       []string
+	RemoveTags    []string
+	SetMetadata   map[string]interface{}
+	UnsetMetadata []string
+	Access        AccessEnum
+
+	// Concurrency bounds the number of files updated at the same time
+	Concurrency int
+	// DryRun reports the changes without updating any file
+	DryRun bool
+}
+
+/*
+summary: Edit tags, metadata and access of many files
+
+description: Select files with a ListFiles query or a folder and apply the same
+tag, metadata and access changes to all of them. Files are updated concurrently and
+files which would not change are left untouched. A failure on one file does not stop
+the others, every file gets its own entry in the results.
+Set DryRun to preview the changes.
+
+params: BulkEditXQuery
+*/
+func (c *Assets) BulkEdit(
+	p BulkEditXQuery,
+) (*BulkEditResponse, error) {
+
+	if p.Access != "" {
+		if err := p.Access.IsValid(); err != nil {
+			return nil, common.NewFDKError(err.Error())
+		}
+	}
+	if len(p.AddTags) == 0 && len(p.RemoveTags) == 0 && len(p.SetMetadata) == 0 && len(p.UnsetMetadata) == 0 && p.Access == "" {
+		return nil, common.NewFDKError("at least one change is required for a bulk edit")
+	}
+	fileIds, err := c.selectFiles(p.Query, p.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkEditResult, len(fileIds))
+	forEachConcurrently(len(fileIds), p.Concurrency, func(i int) {
+		results[i] = c.bulkEditFile(fileIds[i], p)
+	})
+
+	response := &BulkEditResponse{DryRun: p.DryRun, Results: results}
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			response.Failed++
+		case result.Changed:
+			response.Changed++
+		default:
+			response.Unchanged++
+		}
+	}
+	return response, nil
+}
+
+func (c *Assets) bulkEditFile(fileId string, p BulkEditXQuery) BulkEditResult {
+	result := BulkEditResult{FileId: fileId}
+	file, err := c.getFile(fileId)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Before = AssetAttributes{Access: file.Access, Tags: file.Tags, Metadata: file.Metadata}
+	result.After = editAttributes(result.Before, p)
+	result.Changed = !reflect.DeepEqual(normalizeAttributes(result.Before), normalizeAttributes(result.After))
+	if !result.Changed || p.DryRun {
+		return result
+	}
+	_, err = c.patchFile(fileId, map[string]interface{}{
+		"access":   result.After.Access,
+		"tags":     result.After.Tags,
+		"metadata": result.After.Metadata,
+	})
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// editAttributes returns a copy of attributes with the changes of a bulk edit applied
+func editAttributes(attributes AssetAttributes, p BulkEditXQuery) AssetAttributes {
+	removed := map[string]bool{}
+	for _, tag := range p.RemoveTags {
+		removed[tag] = true
+	}
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, tag := range append(append([]string{}, attributes.Tags...), p.AddTags...) {
+		if removed[tag] || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	metadata := map[string]interface{}{}
+	for key, value := range attributes.Metadata {
+		metadata[key] = value
+	}
+	for key, value := range p.SetMetadata {
+		metadata[key] = value
+	}
+	for _, key := range p.UnsetMetadata {
+		delete(metadata, key)
+	}
+
+	access := attributes.Access
+	if p.Access != "" {
+		access = p.Access
+	}
+	return AssetAttributes{Access: access, Tags: tags, Metadata: metadata}
+}
+
+// normalizeAttributes makes nil and empty tags and metadata compare equal
+func normalizeAttributes(attributes AssetAttributes) AssetAttributes {
+	if attributes.Tags == nil {
+		attributes.Tags = []string{}
+	}
+	if attributes.Metadata == nil {
+		attributes.Metadata = map[string]interface{}{}
+	}
+	return attributes
+}
@@ -0,0 +1,239 @@
+package platform
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+// defaultCatalogueTTL is how long a fetched module catalogue is used when no TTL is set
+const defaultCatalogueTTL = time.Hour
+
+// CatalogueCacheOptions configure how a client caches the module catalogue of GetModules
+type CatalogueCacheOptions struct {
+	// TTL is how long the catalogue is used before it is fetched again, defaults to an hour
+	TTL time.Duration
+	// SnapshotPath is a file the catalogue is saved to after every fetch. A snapshot younger
+	// than the TTL is used instead of fetching, an older one when the fetch fails.
+	SnapshotPath string
+	// Embedded is a snapshot shipped with the program, such as a file read with go:embed.
+	// It is used when the catalogue cannot be fetched and there is no other copy.
+	Embedded []byte
+}
+
+// CatalogueChanges lists the plugins and operations added or removed since the previous catalogue.
+// Operations are written as plugin.method.
+type CatalogueChanges struct {
+	AddedPlugins      []string `json:"addedPlugins"`
+	RemovedPlugins    []string `json:"removedPlugins"`
+	AddedOperations   []string `json:"addedOperations"`
+	RemovedOperations []string `json:"removedOperations"`
+	// SnapshotError is set when the fetched catalogue could not be saved to the snapshot
+	SnapshotError string `json:"snapshotError,omitempty"`
+}
+
+// Changed reports whether any plugin or operation was added or removed
+func (c *CatalogueChanges) Changed() bool {
+	return len(c.AddedPlugins)+len(c.RemovedPlugins)+len(c.AddedOperations)+len(c.RemovedOperations) > 0
+}
+
+// catalogueCache holds the module catalogue of a client
+type catalogueCache struct {
+	mu        sync.Mutex
+	options   CatalogueCacheOptions
+	catalogue *url.Catalogue
+	loadedAt  time.Time
+}
+
+// SetCatalogueCache configures the cache of the module catalogue and drops the catalogue held in memory
+func (c *Assets) SetCatalogueCache(options CatalogueCacheOptions) error {
+	if options.TTL < 0 {
+		return common.NewFDKError("TTL cannot be negative")
+	}
+	if len(options.Embedded) > 0 {
+		if _, err := url.ParseCatalogue(options.Embedded); err != nil {
+			return common.NewFDKError("invalid embedded catalogue: " + err.Error())
+		}
+	}
+	c.catalogue.mu.Lock()
+	defer c.catalogue.mu.Unlock()
+	c.catalogue.options = options
+	c.catalogue.catalogue = nil
+	return nil
+}
+
+// Catalogue returns the module catalogue of GetModules with typed plugins, operations and params.
+// It is kept in memory for the TTL of the cache, and read from the snapshot on disk while the
+// snapshot is younger than the TTL. When it cannot be fetched, the previous catalogue, the snapshot
+// or the embedded snapshot is used instead, in that order.
+func (c *Assets) Catalogue() (*url.Catalogue, error) {
+	cache := &c.catalogue
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	ttl := cache.ttl()
+	if cache.catalogue != nil && time.Since(cache.loadedAt) < ttl {
+		return cache.catalogue, nil
+	}
+	if path := cache.options.SnapshotPath; path != "" {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < ttl {
+			if catalogue, err := readCatalogueSnapshot(path); err == nil {
+				cache.catalogue, cache.loadedAt = catalogue, info.ModTime()
+				return catalogue, nil
+			}
+		}
+	}
+
+	catalogue, data, err := c.fetchCatalogue()
+	if err == nil {
+		// the snapshot is only a cache, the fetched catalogue is used when it cannot be saved
+		cache.saveSnapshot(data)
+		return catalogue, nil
+	}
+	fallback := cache.catalogue
+	if fallback == nil {
+		fallback = cache.stored()
+	}
+	if fallback == nil {
+		return nil, err
+	}
+	// the fallback is used for a TTL before fetching again
+	cache.catalogue, cache.loadedAt = fallback, time.Now()
+	return fallback, nil
+}
+
+// RefreshCatalogue fetches the module catalogue regardless of the TTL, saves the snapshot and reports
+// the plugins and operations added or removed since the previous catalogue. The previous catalogue is
+// the one in memory, else the snapshot on disk, else the embedded snapshot. Without any of them every
+// plugin and operation is reported as added. Failing to save the snapshot is reported in SnapshotError.
+func (c *Assets) RefreshCatalogue() (*CatalogueChanges, error) {
+	cache := &c.catalogue
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	previous := cache.catalogue
+	if previous == nil {
+		previous = cache.stored()
+	}
+	catalogue, data, err := c.fetchCatalogue()
+	if err != nil {
+		return nil, err
+	}
+	changes := catalogueChanges(previous, catalogue)
+	if err := cache.saveSnapshot(data); err != nil {
+		changes.SnapshotError = err.Error()
+	}
+	return changes, nil
+}
+
+// fetchCatalogue fetches the catalogue and keeps it in memory, it returns the JSON to save
+// to the snapshot. The cache lock is held.
+func (c *Assets) fetchCatalogue() (*url.Catalogue, []byte, error) {
+	modules, err := c.GetModules(GetModulesXQuery{})
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := json.MarshalIndent(modules, "", "  ")
+	if err != nil {
+		return nil, nil, common.NewFDKError(err.Error())
+	}
+	catalogue, err := url.ParseCatalogue(data)
+	if err != nil {
+		return nil, nil, common.NewFDKError(err.Error())
+	}
+	c.catalogue.catalogue, c.catalogue.loadedAt = catalogue, time.Now()
+	return catalogue, data, nil
+}
+
+// saveSnapshot writes a fetched catalogue to the snapshot file, if any
+func (cache *catalogueCache) saveSnapshot(data []byte) error {
+	if cache.options.SnapshotPath == "" {
+		return nil
+	}
+	return os.WriteFile(cache.options.SnapshotPath, data, 0o644)
+}
+
+func (cache *catalogueCache) ttl() time.Duration {
+	if cache.options.TTL > 0 {
+		return cache.options.TTL
+	}
+	return defaultCatalogueTTL
+}
+
+// stored returns the catalogue of the snapshot on disk, else of the embedded snapshot, nil without either
+func (cache *catalogueCache) stored() *url.Catalogue {
+	if cache.options.SnapshotPath != "" {
+		if catalogue, err := readCatalogueSnapshot(cache.options.SnapshotPath); err == nil {
+			return catalogue
+		}
+	}
+	if len(cache.options.Embedded) > 0 {
+		if catalogue, err := url.ParseCatalogue(cache.options.Embedded); err == nil {
+			return catalogue
+		}
+	}
+	return nil
+}
+
+func readCatalogueSnapshot(path string) (*url.Catalogue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return url.ParseCatalogue(data)
+}
+
+// catalogueChanges compares the plugins and operations of two catalogues, previous can be nil
+func catalogueChanges(previous, current *url.Catalogue) *CatalogueChanges {
+	if previous == nil {
+		previous = &url.Catalogue{}
+	}
+	changes := &CatalogueChanges{
+		AddedPlugins:      []string{},
+		RemovedPlugins:    []string{},
+		AddedOperations:   []string{},
+		RemovedOperations: []string{},
+	}
+	before, after := catalogueOperations(previous), catalogueOperations(current)
+	for id := range current.Plugins {
+		if _, ok := previous.Plugins[id]; !ok {
+			changes.AddedPlugins = append(changes.AddedPlugins, id)
+		}
+	}
+	for id := range previous.Plugins {
+		if _, ok := current.Plugins[id]; !ok {
+			changes.RemovedPlugins = append(changes.RemovedPlugins, id)
+		}
+	}
+	for operation := range after {
+		if !before[operation] {
+			changes.AddedOperations = append(changes.AddedOperations, operation)
+		}
+	}
+	for operation := range before {
+		if !after[operation] {
+			changes.RemovedOperations = append(changes.RemovedOperations, operation)
+		}
+	}
+	sort.Strings(changes.AddedPlugins)
+	sort.Strings(changes.RemovedPlugins)
+	sort.Strings(changes.AddedOperations)
+	sort.Strings(changes.RemovedOperations)
+	return changes
+}
+
+// catalogueOperations returns the operations of a catalogue as plugin.method
+func catalogueOperations(catalogue *url.Catalogue) map[string]bool {
+	operations := map[string]bool{}
+	for id, plugin := range catalogue.Plugins {
+		for _, operation := range plugin.Operations {
+			operations[id+"."+operation.Method] = true
+		}
+	}
+	return operations
+}
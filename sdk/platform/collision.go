@@ -0,0 +1,121 @@
+package platform
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+// VersionsFolder is the folder next to a file where the VERSION_ARCHIVE strategy keeps replaced versions
+const VersionsFolder = ".versions"
+
+// collisionPlan holds the upload flags for a collision strategy, or the existing
+// file when the upload has to be skipped
+type collisionPlan struct {
+	overwrite        bool
+	filenameOverride bool
+	existing         map[string]interface{}
+	// archived is the file moved into VersionsFolder, it is moved back when the upload fails
+	archived *ExploreItem
+	// archivedId is the fileId of the archived file in VersionsFolder
+	archivedId string
+}
+
+// planCollision resolves a collision strategy into the Overwrite and FilenameOverride
+// upload flags. An empty strategy keeps the flags as they are. For SKIP_IF_EXISTS the
+// existing file is returned, and for VERSION_ARCHIVE it is moved into VersionsFolder.
+// Signed urls are uploaded to later, so they cannot archive the file they replace.
+func (c *Assets) planCollision(
+	strategy CollisionStrategyEnum,
+	overwrite, filenameOverride bool,
+	folderPath, name, format string,
+	signed bool,
+) (*collisionPlan, error) {
+	if strategy == "" {
+		return &collisionPlan{overwrite: overwrite, filenameOverride: filenameOverride}, nil
+	}
+	if err := strategy.IsValid(); err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	if overwrite || filenameOverride {
+		return nil, common.NewFDKError("Collision cannot be combined with Overwrite or FilenameOverride")
+	}
+	if strategy == VERSION_ARCHIVE && signed {
+		return nil, common.NewFDKError("VERSION_ARCHIVE cannot be used with signed urls, the file is uploaded after the url is created")
+	}
+
+	switch strategy {
+	case OVERWRITE:
+		return &collisionPlan{overwrite: true}, nil
+	case AUTO_SUFFIX:
+		return &collisionPlan{filenameOverride: true}, nil
+	case SKIP_IF_EXISTS, VERSION_ARCHIVE:
+		if name == "" {
+			// the name is picked by Pixelbin, there is nothing to collide with
+			return &collisionPlan{}, nil
+		}
+		existing, err := c.findFile(folderPath, name, format)
+		if err != nil || existing == nil {
+			return &collisionPlan{}, err
+		}
+		if strategy == SKIP_IF_EXISTS {
+			resp, err := c.GetFileByFileId(GetFileByFileIdXQuery{FileId: existing.FileId})
+			if err != nil {
+				return nil, err
+			}
+			return &collisionPlan{existing: resp}, nil
+		}
+		archived, err := c.archiveVersion(existing)
+		if err != nil {
+			return nil, err
+		}
+		return &collisionPlan{overwrite: true, archived: existing, archivedId: archived.FileId}, nil
+	}
+	return &collisionPlan{}, nil
+}
+
+// uploadFailed moves the file archived by the plan back in place after the upload failed
+func (c *Assets) uploadFailed(plan *collisionPlan, err error) error {
+	if plan.archived == nil {
+		return err
+	}
+	_, restoreErr := c.patchFile(plan.archivedId, map[string]interface{}{"path": plan.archived.Path, "name": plan.archived.Name})
+	if restoreErr != nil {
+		return common.NewFDKError(fmt.Sprintf("%v, the archived version %s could not be restored: %v", err, plan.archivedId, restoreErr))
+	}
+	return err
+}
+
+// archiveVersion moves a file into the VersionsFolder next to it, suffixing its name with the current time
+func (c *Assets) archiveVersion(file *ExploreItem) (*FilesResponse, error) {
+	versionsPath := path.Join(file.Path, VersionsFolder)
+	if _, err := c.EnsureFolder(EnsureFolderXQuery{Path: versionsPath}); err != nil {
+		return nil, err
+	}
+	version := strings.Replace(time.Now().UTC().Format("20060102T150405.000Z"), ".", "", 1)
+	return c.patchFile(file.FileId, map[string]interface{}{"path": versionsPath, "name": file.Name + "_" + version})
+}
+
+// uploadName returns the name and format of an upload, falling back to the name of
+// the uploaded file or url when no name is given
+func uploadName(name, source string) (string, string) {
+	_, base, format := path.ParseFileId(source)
+	if name == "" {
+		name = base
+	}
+	return name, format
+}
+
+// urlFileName returns the last segment of the path of a url
+func urlFileName(rawUrl string) string {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+	_, last := path.Split(parsed.Path)
+	return last
+}
@@ -0,0 +1,245 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+type GetCredentialSchemaXQuery struct {
+	PluginId string
+}
+
+/*
+summary: Get the credentials schema of a transformation module
+
+description: Get the credentials a transformation module expects from the credentials of
+GetModule, with the keys, their types and whether they are required.
+
+params: GetCredentialSchemaXQuery
+*/
+func (c *Assets) GetCredentialSchema(
+	p GetCredentialSchemaXQuery,
+) (*CredentialSchema, error) {
+
+	if p.PluginId == "" {
+		return nil, common.NewFDKError("PluginId is required")
+	}
+	resp, err := c.GetModule(GetModuleXQuery{Identifier: p.PluginId})
+	if err != nil {
+		return nil, err
+	}
+	var module TransformationModuleResponse
+	if err := decodeResponse(resp, &module); err != nil {
+		return nil, err
+	}
+	return parseCredentialSchema(p.PluginId, module.Credentials)
+}
+
+// parseCredentialSchema reads the credentials of a module, whose params are a list or a single object
+func parseCredentialSchema(pluginId string, credentials map[string]interface{}) (*CredentialSchema, error) {
+	schema := &CredentialSchema{PluginId: pluginId, Params: []CredentialParam{}}
+	schema.Required, _ = credentials["required"].(bool)
+	params := credentials["params"]
+	if single, ok := params.(map[string]interface{}); ok {
+		params = []interface{}{single}
+	}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, common.NewFDKError(err.Error())
+		}
+		if err := json.Unmarshal(data, &schema.Params); err != nil {
+			return nil, common.NewFDKError(fmt.Sprintf("invalid credentials schema of %s: %s", pluginId, err.Error()))
+		}
+	}
+	return schema, nil
+}
+
+// Validate checks credentials against the schema. Required keys must be set and values must match
+// their type, keys the schema does not declare are reported when it declares any. All the problems
+// are reported in a single error.
+func (s *CredentialSchema) Validate(credentials map[string]interface{}) error {
+	problems := []string{}
+	if s.Required && len(credentials) == 0 {
+		problems = append(problems, "credentials are required")
+	}
+	declared := map[string]bool{}
+	for _, param := range s.Params {
+		declared[param.Identifier] = true
+		value, ok := credentials[param.Identifier]
+		if !ok || value == nil || value == "" {
+			if param.Required {
+				problems = append(problems, fmt.Sprintf("%s is required", param.Identifier))
+			}
+			continue
+		}
+		if problem := checkCredential(param, value); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s %s", param.Identifier, problem))
+		}
+	}
+	if len(s.Params) > 0 {
+		unknown := []string{}
+		for key := range credentials {
+			if !declared[key] {
+				unknown = append(unknown, key)
+			}
+		}
+		sort.Strings(unknown)
+		for _, key := range unknown {
+			problems = append(problems, fmt.Sprintf("%s is not a credential of %s", key, s.PluginId))
+		}
+	}
+	if len(problems) > 0 {
+		return common.NewFDKError(fmt.Sprintf("invalid credentials for %s: %s", s.PluginId, strings.Join(problems, "; ")))
+	}
+	return nil
+}
+
+// checkCredential returns the problem of a value which does not match the type of its param, or an empty string
+func checkCredential(param CredentialParam, value interface{}) string {
+	switch param.Type {
+	case url.ParamString:
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("must be a string, got %T", value)
+		}
+	case url.ParamInteger, url.ParamFloat:
+		number, ok := credentialNumber(value)
+		if param.Type == url.ParamInteger && (!ok || number != math.Trunc(number)) {
+			return fmt.Sprintf("must be an integer, got %v", value)
+		}
+		if !ok {
+			return fmt.Sprintf("must be a number, got %v", value)
+		}
+	case url.ParamBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("must be a boolean, got %T", value)
+		}
+	case url.ParamEnum:
+		text, _ := value.(string)
+		for _, allowed := range param.Enum {
+			if text == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s, got %v", strings.Join(param.Enum, ", "), value)
+	}
+	return ""
+}
+
+func credentialNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	}
+	return 0, false
+}
+
+type ValidateCredentialsXQuery struct {
+	PluginId    string
+	Credentials map[string]interface{}
+}
+
+/*
+summary: Validate credentials of a transformation module
+
+description: Check credentials against the credentials schema of the module before passing them to
+AddCredentials or UpdateCredentials. The error lists every missing key and every value of the wrong type.
+
+params: ValidateCredentialsXQuery
+*/
+func (c *Assets) ValidateCredentials(
+	p ValidateCredentialsXQuery,
+) error {
+
+	schema, err := c.GetCredentialSchema(GetCredentialSchemaXQuery{PluginId: p.PluginId})
+	if err != nil {
+		return err
+	}
+	return schema.Validate(p.Credentials)
+}
+
+type RotateCredentialsXQuery struct {
+	PluginId string
+	// Credentials are the new credentials, validated against the schema of the module first
+	Credentials map[string]interface{}
+	// Previous are the credentials in use, restored when Check fails. They are validated against
+	// the schema too, so that a rollback cannot set invalid credentials.
+	Previous map[string]interface{}
+	// Check is called once the new credentials are set, such as to transform a test image.
+	// A panic in Check is recovered and handled like an error.
+	Check func() error
+}
+
+/*
+summary: Rotate credentials of a transformation module
+
+description: Validate the new and previous credentials, set the new ones with UpdateCredentials
+and call Check. When Check fails or panics the previous credentials are set again and the response reports the rollback with the
+error of Check. An error is returned when the credentials are invalid or cannot be updated, or when
+the rollback fails.
+
+params: RotateCredentialsXQuery
+*/
+func (c *Assets) RotateCredentials(
+	p RotateCredentialsXQuery,
+) (*RotateCredentialsResponse, error) {
+
+	if p.Check == nil {
+		return nil, common.NewFDKError("Check is required")
+	}
+	if len(p.Previous) == 0 {
+		return nil, common.NewFDKError("Previous is required to roll back")
+	}
+	schema, err := c.GetCredentialSchema(GetCredentialSchemaXQuery{PluginId: p.PluginId})
+	if err != nil {
+		return nil, err
+	}
+	if err := schema.Validate(p.Credentials); err != nil {
+		return nil, err
+	}
+	if err := schema.Validate(p.Previous); err != nil {
+		return nil, common.NewFDKError("Previous cannot be restored: " + err.Error())
+	}
+	if _, err := c.UpdateCredentials(UpdateCredentialsXQuery{PluginId: p.PluginId, Credentials: p.Credentials}); err != nil {
+		return nil, err
+	}
+	result := &RotateCredentialsResponse{PluginId: p.PluginId, Rotated: true}
+	checkErr := runCredentialCheck(p.Check)
+	if checkErr == nil {
+		return result, nil
+	}
+	result.Rotated = false
+	result.CheckError = checkErr.Error()
+	if _, err := c.UpdateCredentials(UpdateCredentialsXQuery{PluginId: p.PluginId, Credentials: p.Previous}); err != nil {
+		return result, common.NewFDKError(fmt.Sprintf("check failed: %s, and the previous credentials could not be restored: %s", checkErr.Error(), err.Error()))
+	}
+	result.RolledBack = true
+	return result, nil
+}
+
+// runCredentialCheck calls check, returning a panic as an error so that the credentials are rolled back
+func runCredentialCheck(check func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("check panicked: %v", r)
+		}
+	}()
+	return check()
+}
@@ -0,0 +1,201 @@
+package platform
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+// defaultDeleteBatchSize is the number of ids sent in a single delete request
+const defaultDeleteBatchSize = 100
+
+type DeleteTreeXQuery struct {
+	// Path of the folder to delete along with everything below it
+	Path string
+	// DryRun returns the manifest of what would be deleted without deleting anything
+	DryRun bool
+	// BatchSize is the number of ids sent per delete request, defaults to 100
+	BatchSize int
+	// Concurrency bounds the number of delete requests sent at the same time
+	Concurrency int
+	// RestoreManifest is a file the details of every file are written to before deleting
+	RestoreManifest string
+}
+
+/*
+summary: Delete a folder tree
+
+description: Walk the folder at Path and delete all its files in batches, then the
+folders themselves. The response holds a manifest of the files and folders found
+and reports every file or folder that could not be deleted.
+Set DryRun to only build the manifest, and RestoreManifest to keep the path,
+tags, metadata and access of the deleted files.
+
+params: DeleteTreeXQuery
+*/
+func (c *Assets) DeleteTree(
+	p DeleteTreeXQuery,
+) (*DeleteTreeResponse, error) {
+
+	root := path.Normalize(p.Path)
+	if root == "" {
+		return nil, common.NewFDKError("Path is required, the root folder cannot be deleted")
+	}
+	parent, name := path.Split(root)
+	folder, err := c.findFolder(parent, name)
+	if err != nil {
+		return nil, err
+	}
+	if folder == nil {
+		return nil, common.NewFDKError("folder " + root + " does not exist")
+	}
+
+	manifest := DeleteManifest{Path: root, FileIds: []string{}, Paths: []string{}}
+	files := []ExploreItem{}
+	folders := []string{}
+	err = c.Walk(WalkXQuery{Path: root}, func(item ExploreItem) error {
+		if item.Type == "folder" {
+			folders = append(folders, path.Join(item.Path, item.Name))
+			manifest.Folders++
+			manifest.Paths = append(manifest.Paths, path.Join(item.Path, item.Name))
+			return nil
+		}
+		files = append(files, item)
+		manifest.Files++
+		manifest.Bytes += int64(item.Size)
+		manifest.FileIds = append(manifest.FileIds, item.FileId)
+		manifest.Paths = append(manifest.Paths, item.FileId)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DeleteTreeResponse{DryRun: p.DryRun, Manifest: manifest, Failures: []DeleteFailure{}}
+	if p.DryRun {
+		return result, nil
+	}
+	if p.RestoreManifest != "" {
+		if err := c.writeRestoreManifest(p.RestoreManifest, root, folders, files, p.Concurrency); err != nil {
+			return result, err
+		}
+	}
+
+	batches := splitBatches(files, p.BatchSize)
+	var mu sync.Mutex
+	forEachConcurrently(len(batches), p.Concurrency, func(i int) {
+		deleted, failures := c.deleteBatch(batches[i])
+		mu.Lock()
+		defer mu.Unlock()
+		result.Deleted += deleted
+		result.Failures = append(result.Failures, failures...)
+	})
+
+	if len(result.Failures) > 0 {
+		// deleting the folder would also delete the files that failed
+		return result, nil
+	}
+	if _, err := c.DeleteFolder(DeleteFolderXQuery{ID: folder.ID}); err != nil {
+		result.Failures = append(result.Failures, DeleteFailure{ID: folder.ID, Path: root, Error: err.Error()})
+	}
+	return result, nil
+}
+
+// splitBatches splits files into delete batches of at most batchSize files
+func splitBatches(files []ExploreItem, batchSize int) [][]ExploreItem {
+	if batchSize <= 0 {
+		batchSize = defaultDeleteBatchSize
+	}
+	batches := [][]ExploreItem{}
+	for start := 0; start < len(files); start += batchSize {
+		end := start + batchSize
+		if end > len(files) {
+			end = len(files)
+		}
+		batches = append(batches, files[start:end])
+	}
+	return batches
+}
+
+// deleteBatch deletes a batch of files, reporting every file missing from the response as failed
+func (c *Assets) deleteBatch(files []ExploreItem) (int, []DeleteFailure) {
+	ids := make([]string, len(files))
+	for i, file := range files {
+		ids[i] = file.ID
+	}
+	failures := []DeleteFailure{}
+	deleted, err := c.deleteFiles(ids)
+	if err != nil {
+		for _, file := range files {
+			failures = append(failures, DeleteFailure{ID: file.ID, Path: file.FileId, Error: err.Error()})
+		}
+		return 0, failures
+	}
+	done := map[string]bool{}
+	for _, file := range deleted {
+		done[file.ID] = true
+	}
+	for _, file := range files {
+		if !done[file.ID] {
+			failures = append(failures, DeleteFailure{ID: file.ID, Path: file.FileId, Error: "file was not deleted"})
+		}
+	}
+	return len(files) - len(failures), failures
+}
+
+// deleteFiles calls the delete files endpoint, which responds with the list of deleted files
+func (c *Assets) deleteFiles(ids []string) ([]FilesResponse, error) {
+	apiClient := &APIClient{
+		Conf:        c.config,
+		Method:      "post",
+		Url:         "/service/platform/assets/v1.0/files/delete",
+		Query:       map[string]string{},
+		Body:        map[string]interface{}{"ids": ids},
+		ContentType: "application/json",
+	}
+	response, err := apiClient.Execute()
+	if err != nil {
+		return nil, err
+	}
+	deleted := []FilesResponse{}
+	if err := json.Unmarshal(response, &deleted); err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	return deleted, nil
+}
+
+// writeRestoreManifest fetches the details of the files about to be deleted and writes them to manifestPath
+func (c *Assets) writeRestoreManifest(manifestPath, root string, folders []string, files []ExploreItem, concurrency int) error {
+	restore := RestoreManifest{
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Path:      root,
+		Folders:   folders,
+		Files:     make([]FilesResponse, len(files)),
+	}
+	errs := make([]error, len(files))
+	forEachConcurrently(len(files), concurrency, func(i int) {
+		file, err := c.getFile(files[i].FileId)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		restore.Files[i] = *file
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(restore, "", "  ")
+	if err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	if err := os.WriteFile(manifestPath, data, 0o644); err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	return nil
+}
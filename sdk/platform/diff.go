@@ -0,0 +1,416 @@
+package platform
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+const (
+	diffTypeFile   = "file"
+	diffTypeFolder = "folder"
+	diffTypePreset = "preset"
+)
+
+type DiffXQuery struct {
+	// Path of the source folder, empty for the whole organization
+	Path string
+	// Target is the client of the organization compared with, nil for the same organization
+	Target *Assets
+	// TargetPath is the folder compared with Path, defaults to Path
+	TargetPath string
+	// IncludePresets compares the presets of both organizations as well
+	IncludePresets bool
+	// Concurrency bounds the number of files fetched at the same time on each side
+	Concurrency int
+}
+
+/*
+summary: Compare two asset trees
+
+description: Compare the tree below Path with the tree below TargetPath, possibly in another
+organization. Files are compared by path, size, access, tags and metadata, and presets by
+transformation and params. Entries are added when they only exist in the source,
+removed when they only exist in the target and changed when they differ.
+Entry paths are relative to Path and TargetPath.
+
+params: DiffXQuery
+*/
+func (c *Assets) Diff(
+	p DiffXQuery,
+) (*DiffResponse, error) {
+
+	target := p.Target
+	if target == nil {
+		target = c
+	}
+	sourcePath := path.Normalize(p.Path)
+	targetPath := sourcePath
+	if p.TargetPath != "" {
+		targetPath = path.Normalize(p.TargetPath)
+	}
+
+	source, err := c.snapshotTree(sourcePath, p.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := target.snapshotTree(targetPath, p.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResponse{Path: sourcePath, TargetPath: targetPath, Entries: []DiffEntry{}}
+	for folder := range source.folders {
+		if !existing.folders[folder] {
+			result.add(DiffEntry{Type: diffTypeFolder, Change: ADDED, Path: folder})
+		}
+	}
+	for folder := range existing.folders {
+		if !source.folders[folder] {
+			result.add(DiffEntry{Type: diffTypeFolder, Change: REMOVED, Path: folder})
+		}
+	}
+	for fileId, file := range source.files {
+		other, ok := existing.files[fileId]
+		if !ok {
+			result.add(DiffEntry{Type: diffTypeFile, Change: ADDED, Path: fileId})
+		} else if fields := diffFileFields(file, other); len(fields) > 0 {
+			result.add(DiffEntry{Type: diffTypeFile, Change: CHANGED, Path: fileId, Fields: fields})
+		}
+	}
+	for fileId := range existing.files {
+		if _, ok := source.files[fileId]; !ok {
+			result.add(DiffEntry{Type: diffTypeFile, Change: REMOVED, Path: fileId})
+		}
+	}
+
+	if p.IncludePresets {
+		if err := result.diffPresets(c, target); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(result.Entries, func(i, j int) bool {
+		if result.Entries[i].Type != result.Entries[j].Type {
+			return result.Entries[i].Type > result.Entries[j].Type
+		}
+		return result.Entries[i].Path < result.Entries[j].Path
+	})
+	return result, nil
+}
+
+// String lists the entries of the diff, one per line, prefixed with +, ~ or -
+func (d *DiffResponse) String() string {
+	var b strings.Builder
+	for _, entry := range d.Entries {
+		prefix := map[DiffChangeEnum]string{ADDED: "+", CHANGED: "~", REMOVED: "-"}[entry.Change]
+		fmt.Fprintf(&b, "%s %s %s", prefix, entry.Type, entry.Path)
+		if len(entry.Fields) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(entry.Fields, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (d *DiffResponse) add(entry DiffEntry) {
+	switch entry.Change {
+	case ADDED:
+		d.Added++
+	case CHANGED:
+		d.Changed++
+	case REMOVED:
+		d.Removed++
+	}
+	d.Entries = append(d.Entries, entry)
+}
+
+func (d *DiffResponse) diffPresets(source, target *Assets) error {
+	sourcePresets, err := source.listPresets()
+	if err != nil {
+		return err
+	}
+	targetPresets, err := target.listPresets()
+	if err != nil {
+		return err
+	}
+	existing := map[string]AddPresetResponse{}
+	for _, preset := range targetPresets {
+		existing[preset.PresetName] = preset
+	}
+	names := map[string]bool{}
+	for _, preset := range sourcePresets {
+		names[preset.PresetName] = true
+		other, ok := existing[preset.PresetName]
+		if !ok {
+			d.add(DiffEntry{Type: diffTypePreset, Change: ADDED, Path: preset.PresetName})
+			continue
+		}
+		fields := []string{}
+		if preset.Transformation != other.Transformation {
+			fields = append(fields, "transformation")
+		}
+		if !reflect.DeepEqual(nonNilMap(preset.Params), nonNilMap(other.Params)) {
+			fields = append(fields, "params")
+		}
+		if len(fields) > 0 {
+			d.add(DiffEntry{Type: diffTypePreset, Change: CHANGED, Path: preset.PresetName, Fields: fields})
+		}
+	}
+	for _, preset := range targetPresets {
+		if !names[preset.PresetName] {
+			d.add(DiffEntry{Type: diffTypePreset, Change: REMOVED, Path: preset.PresetName})
+		}
+	}
+	return nil
+}
+
+// diffFileFields returns the names of the fields that differ between two files
+func diffFileFields(file, other *FilesResponse) []string {
+	fields := []string{}
+	if file.Size != other.Size {
+		fields = append(fields, "size")
+	}
+	if file.Access != other.Access {
+		fields = append(fields, "access")
+	}
+	tags, otherTags := append([]string{}, file.Tags...), append([]string{}, other.Tags...)
+	sort.Strings(tags)
+	sort.Strings(otherTags)
+	if !reflect.DeepEqual(tags, otherTags) {
+		fields = append(fields, "tags")
+	}
+	if !reflect.DeepEqual(nonNilMap(file.Metadata), nonNilMap(other.Metadata)) {
+		fields = append(fields, "metadata")
+	}
+	return fields
+}
+
+func nonNilMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+// treeSnapshot holds the folders and file details below a folder, keyed by relative path
+type treeSnapshot struct {
+	folders map[string]bool
+	files   map[string]*FilesResponse
+}
+
+func (c *Assets) snapshotTree(root string, concurrency int) (*treeSnapshot, error) {
+	snapshot := &treeSnapshot{folders: map[string]bool{}, files: map[string]*FilesResponse{}}
+	items := []ExploreItem{}
+	err := c.Walk(WalkXQuery{Path: root}, func(item ExploreItem) error {
+		if item.Type == "folder" {
+			snapshot.folders[path.Rebase(path.Join(item.Path, item.Name), root, "")] = true
+		} else {
+			items = append(items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*FilesResponse, len(items))
+	errs := make([]error, len(items))
+	forEachConcurrently(len(items), concurrency, func(i int) {
+		files[i], errs[i] = c.getFile(items[i].FileId)
+	})
+	for i, file := range files {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		snapshot.files[path.Rebase(items[i].FileId, root, "")] = file
+	}
+	return snapshot, nil
+}
+
+type ApplyDiffXQuery struct {
+	// Diff is the result of Diff called on the source client
+	Diff *DiffResponse
+	// Target is the client the diff is applied to, nil for the same organization
+	Target *Assets
+	// Delete removes the entries that only exist in the target
+	Delete bool
+	// SignToken and SignTokenID are used to fetch private assets of the source
+	SignTokenID int
+	SignToken   string
+	// Concurrency bounds the number of files copied at the same time
+	Concurrency int
+}
+
+/*
+summary: Apply a diff to the target
+
+description: Copy the added and changed entries of a diff from the source organization to the target.
+Files are re-ingested with UrlUpload from their source CDN url, files whose content is unchanged
+only get their access, tags and metadata updated, and changed presets are replaced.
+Removed entries are only deleted from the target when Delete is set, otherwise they are skipped.
+
+params: ApplyDiffXQuery
+*/
+func (c *Assets) ApplyDiff(
+	p ApplyDiffXQuery,
+) (*ApplyDiffResponse, error) {
+
+	if p.Diff == nil {
+		return nil, common.NewFDKError("Diff is required")
+	}
+	target := p.Target
+	if target == nil {
+		target = c
+	}
+	result := &ApplyDiffResponse{Failures: []ApplyDiffFailure{}}
+	var mu sync.Mutex
+	record := func(entry DiffEntry, applied bool, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err != nil:
+			result.Failures = append(result.Failures, ApplyDiffFailure{Type: entry.Type, Path: entry.Path, Error: err.Error()})
+		case applied:
+			result.Applied++
+		default:
+			result.Skipped++
+		}
+	}
+
+	// folders and presets first so that files land in existing folders, then
+	// removed files before the folders that contained them
+	staged := [][]DiffEntry{{}, {}, {}, {}}
+	for _, entry := range p.Diff.Entries {
+		stage := 0
+		switch {
+		case entry.Change == REMOVED && entry.Type == diffTypeFile:
+			stage = 2
+		case entry.Change == REMOVED:
+			stage = 3
+		case entry.Type == diffTypeFile:
+			stage = 1
+		}
+		staged[stage] = append(staged[stage], entry)
+	}
+	removedFolders := map[string]bool{}
+	for _, entry := range staged[3] {
+		if entry.Type == diffTypeFolder {
+			removedFolders[entry.Path] = true
+		}
+	}
+	for _, entries := range staged {
+		forEachConcurrently(len(entries), p.Concurrency, func(i int) {
+			entry := entries[i]
+			if entry.Change == REMOVED && entry.Type == diffTypeFolder && p.Delete {
+				// deleting a folder deletes its subfolders as well
+				for parent, _ := path.Split(entry.Path); parent != ""; parent, _ = path.Split(parent) {
+					if removedFolders[parent] {
+						record(entry, true, nil)
+						return
+					}
+				}
+			}
+			applied, err := c.applyDiffEntry(target, entry, p)
+			record(entry, applied, err)
+		})
+	}
+	return result, nil
+}
+
+// applyDiffEntry applies a single entry, reporting false when it was skipped
+func (c *Assets) applyDiffEntry(target *Assets, entry DiffEntry, p ApplyDiffXQuery) (bool, error) {
+	sourcePath := path.Join(p.Diff.Path, entry.Path)
+	targetPath := path.Join(p.Diff.TargetPath, entry.Path)
+	if entry.Change == REMOVED && !p.Delete {
+		return false, nil
+	}
+
+	switch entry.Type {
+	case diffTypeFolder:
+		if entry.Change == REMOVED {
+			parent, name := path.Split(targetPath)
+			folder, err := target.findFolder(parent, name)
+			if err != nil || folder == nil {
+				return false, err
+			}
+			_, err = target.DeleteFolder(DeleteFolderXQuery{ID: folder.ID})
+			return err == nil, err
+		}
+		_, err := target.EnsureFolder(EnsureFolderXQuery{Path: targetPath})
+		return err == nil, err
+
+	case diffTypePreset:
+		if entry.Change == REMOVED {
+			_, err := target.DeletePreset(DeletePresetXQuery{PresetName: entry.Path})
+			return err == nil, err
+		}
+		preset, err := c.getPreset(entry.Path)
+		if err != nil {
+			return false, err
+		}
+		replacement := AddPresetXQuery{PresetName: preset.PresetName, Transformation: preset.Transformation, Params: preset.Params}
+		if entry.Change == ADDED {
+			_, err = target.AddPreset(replacement)
+			return err == nil, err
+		}
+		previous, err := target.getPreset(entry.Path)
+		if err != nil {
+			return false, err
+		}
+		err = target.replacePreset(previous, replacement)
+		return err == nil, err
+	}
+
+	if entry.Change == REMOVED {
+		_, err := target.DeleteFile(DeleteFileXQuery{FileId: targetPath})
+		return err == nil, err
+	}
+	file, err := c.getFile(sourcePath)
+	if err != nil {
+		return false, err
+	}
+	if entry.Change == CHANGED && !containsString(entry.Fields, "size") {
+		_, err := target.patchFile(targetPath, map[string]interface{}{
+			"access":   file.Access,
+			"tags":     nonNilStrings(file.Tags),
+			"metadata": nonNilMap(file.Metadata),
+		})
+		return err == nil, err
+	}
+	sourceUrl, err := c.resolveDownloadUrl(DownloadXQuery{File: file, SignToken: p.SignToken, SignTokenID: p.SignTokenID})
+	if err != nil {
+		return false, err
+	}
+	folderPath, name, _ := path.ParseFileId(targetPath)
+	_, err = target.UrlUpload(UrlUploadXQuery{
+		URL:        sourceUrl,
+		Path:       folderPath,
+		Name:       name,
+		Access:     file.Access,
+		Tags:       file.Tags,
+		Metadata:   file.Metadata,
+		Overwrite:  true,
+		skipPolicy: true,
+	})
+	return err == nil, err
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
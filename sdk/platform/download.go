@@ -0,0 +1,329 @@
+package platform
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/cache"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/security"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+// defaultSignExpirySeconds is used for private assets when SignExpirySeconds is not set
+const defaultSignExpirySeconds = 300
+
+type DownloadXQuery struct {
+	// One of FileId, File or URL identifies the asset to download
+	FileId string
+	File   *FilesResponse
+	URL    string
+
+	// Transformations in the form accepted by url.ObjToUrl, applied on top of the asset url
+	Transformations []map[string]interface{}
+	IsCustomDomain  bool
+
+	// Writer receives the downloaded bytes
+	Writer io.Writer
+
+	// Offset resumes a previous download from the given byte position
+	Offset int64
+
+	// Checksum is the expected hex digest of the complete content
+	Checksum          string
+	ChecksumAlgorithm ChecksumAlgorithmEnum
+
+	// SignToken and SignTokenID are used to sign urls of private assets
+	SignTokenID       int
+	SignToken         string
+	SignExpirySeconds int
+
+	// SkipCache bypasses the download cache set with SetDownloadCache
+	SkipCache bool
+}
+
+/*
+summary: Download an asset
+
+description: Stream the original or a transformed rendition of an asset into a writer.
+The asset can be identified by fileId, by a FilesResponse or by its CDN url.
+Set Offset to resume an interrupted download and Checksum to verify the content.
+Private assets are fetched through a signed url built from SignToken and SignTokenID.
+Downloads go through the cache set with SetDownloadCache unless SkipCache is set or Offset is used.
+
+params: DownloadXQuery
+*/
+func (c *Assets) Download(
+	p DownloadXQuery,
+) (*DownloadResponse, error) {
+
+	if p.Writer == nil {
+		return nil, common.NewFDKError("Writer is required to download an asset")
+	}
+	if p.Offset < 0 {
+		return nil, common.NewFDKError("Offset cannot be negative")
+	}
+	var hasher hash.Hash
+	if p.Checksum != "" {
+		var err error
+		hasher, err = newChecksumHash(p.ChecksumAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	downloadUrl, err := c.resolveDownloadUrl(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.downloadCache != nil && !p.SkipCache && p.Offset == 0 {
+		return c.downloadWithCache(downloadUrl, p, hasher)
+	}
+
+	headers := map[string]string{}
+	if p.Offset > 0 {
+		headers["Range"] = fmt.Sprintf("bytes=%d-", p.Offset)
+	}
+	res, err := common.HttpStream("GET", downloadUrl, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if p.Offset > 0 && res.StatusCode == http.StatusOK {
+		// the server ignored the range, skip the bytes the caller already has
+		if _, err := io.CopyN(io.Discard, res.Body, p.Offset); err != nil {
+			return nil, common.NewFDKError(err.Error())
+		}
+	}
+	if hasher != nil && p.Offset > 0 {
+		existing, ok := p.Writer.(io.ReaderAt)
+		if !ok {
+			return nil, common.NewFDKError("Writer must implement io.ReaderAt to verify the checksum of a resumed download")
+		}
+		if _, err := io.Copy(hasher, io.NewSectionReader(existing, 0, p.Offset)); err != nil {
+			return nil, common.NewFDKError(err.Error())
+		}
+	}
+
+	result := newDownloadResponse(downloadUrl, res)
+	return result, copyDownload(result, p.Writer, res.Body, hasher, p.Checksum)
+}
+
+// downloadWithCache serves a download through the download cache, revalidating stale entries
+func (c *Assets) downloadWithCache(downloadUrl string, p DownloadXQuery, hasher hash.Hash) (*DownloadResponse, error) {
+	key, err := cache.CanonicalKey(downloadUrl)
+	if err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	headers := map[string]string{}
+	entry, cached := c.downloadCache.Get(key)
+	if cached && c.downloadCache.Fresh(entry) {
+		if content, entry, err := c.downloadCache.Open(key); err == nil {
+			return serveFromCache(content, entry, downloadUrl, http.StatusOK, p, hasher)
+		}
+		// evicted since the lookup, fetched again below
+	} else if cached {
+		if entry.ETag != "" {
+			headers["If-None-Match"] = entry.ETag
+		}
+		if entry.LastModified != "" {
+			headers["If-Modified-Since"] = entry.LastModified
+		}
+	}
+
+	res, err := common.HttpStream("GET", downloadUrl, headers)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotModified {
+		res.Body.Close()
+		content, entry, err := c.downloadCache.Open(key)
+		if err == nil {
+			if err := c.downloadCache.Revalidated(key); err != nil && !errors.Is(err, os.ErrNotExist) {
+				content.Close()
+				return nil, common.NewFDKError(err.Error())
+			}
+			return serveFromCache(content, entry, downloadUrl, http.StatusNotModified, p, hasher)
+		}
+		// the entry was evicted while the request was in flight, the content is fetched unconditionally
+		res, err = common.HttpStream("GET", downloadUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer res.Body.Close()
+
+	result := newDownloadResponse(downloadUrl, res)
+	if res.StatusCode != http.StatusOK {
+		return result, copyDownload(result, p.Writer, res.Body, hasher, p.Checksum)
+	}
+	writer, err := c.downloadCache.NewWriter(key)
+	if err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	if err := copyDownload(result, io.MultiWriter(p.Writer, writer), res.Body, hasher, p.Checksum); err != nil {
+		writer.Abort()
+		return result, err
+	}
+	err = writer.Commit(cache.Entry{
+		ETag:         result.ETag,
+		LastModified: result.LastModified,
+		ContentType:  result.ContentType,
+	})
+	if err != nil {
+		return result, common.NewFDKError(err.Error())
+	}
+	return result, nil
+}
+
+// serveFromCache writes content opened from the cache and closes it
+func serveFromCache(content io.ReadCloser, entry cache.Entry, downloadUrl string, status int, p DownloadXQuery, hasher hash.Hash) (*DownloadResponse, error) {
+	defer content.Close()
+	result := &DownloadResponse{
+		URL:           downloadUrl,
+		StatusCode:    status,
+		ContentType:   entry.ContentType,
+		ContentLength: entry.Size,
+		ETag:          entry.ETag,
+		LastModified:  entry.LastModified,
+		FromCache:     true,
+	}
+	return result, copyDownload(result, p.Writer, content, hasher, p.Checksum)
+}
+
+func newDownloadResponse(downloadUrl string, res *http.Response) *DownloadResponse {
+	return &DownloadResponse{
+		URL:           downloadUrl,
+		StatusCode:    res.StatusCode,
+		ContentType:   res.Header.Get("Content-Type"),
+		ContentLength: res.ContentLength,
+		ETag:          res.Header.Get("ETag"),
+		LastModified:  res.Header.Get("Last-Modified"),
+	}
+}
+
+// copyDownload copies the content into dst and verifies it against the expected checksum
+func copyDownload(result *DownloadResponse, dst io.Writer, src io.Reader, hasher hash.Hash, expected string) error {
+	if hasher != nil {
+		dst = io.MultiWriter(dst, hasher)
+	}
+	written, err := io.Copy(dst, src)
+	result.BytesWritten = written
+	if err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	if hasher != nil {
+		result.Checksum = hex.EncodeToString(hasher.Sum(nil))
+		if !strings.EqualFold(result.Checksum, expected) {
+			return common.NewFDKError(fmt.Sprintf("checksum mismatch: expected %s, got %s", expected, result.Checksum))
+		}
+	}
+	return nil
+}
+
+// SetDownloadCache serves Download requests through an on-disk cache, pass nil to disable it
+func (c *Assets) SetDownloadCache(downloadCache *cache.DiskCache) {
+	c.downloadCache = downloadCache
+}
+
+// resolveDownloadUrl returns the final url to fetch for a download request
+func (c *Assets) resolveDownloadUrl(p DownloadXQuery) (string, error) {
+	var (
+		rawUrl string
+		access AccessEnum
+	)
+	switch {
+	case p.URL != "":
+		rawUrl = p.URL
+	case p.File != nil:
+		rawUrl, access = p.File.URL, p.File.Access
+	case p.FileId != "":
+		file, err := c.getFile(p.FileId)
+		if err != nil {
+			return "", err
+		}
+		rawUrl, access = file.URL, file.Access
+	default:
+		return "", common.NewFDKError("one of FileId, File or URL is required to download an asset")
+	}
+	if rawUrl == "" {
+		return "", common.NewFDKError("unable to resolve a url for the asset")
+	}
+
+	var err error
+	if len(p.Transformations) > 0 {
+		rawUrl, err = applyTransformations(rawUrl, p.Transformations, p.IsCustomDomain)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if isSignedUrl(rawUrl) {
+		return rawUrl, nil
+	}
+	if p.SignToken != "" {
+		expiry := p.SignExpirySeconds
+		if expiry <= 0 {
+			expiry = defaultSignExpirySeconds
+		}
+		return security.SignURL(rawUrl, expiry, p.SignTokenID, p.SignToken)
+	}
+	if access == PRIVATE {
+		return "", common.NewFDKError("SignToken and SignTokenID are required to download a private asset")
+	}
+	return rawUrl, nil
+}
+
+// applyTransformations replaces the transformation pattern of a pixelbin url
+func applyTransformations(rawUrl string, transformations []map[string]interface{}, isCustomDomain bool) (string, error) {
+	obj, err := url.UrlToObj(rawUrl, url.WithCustomDomain(isCustomDomain))
+	if err != nil {
+		return "", err
+	}
+	if worker, _ := obj["worker"].(bool); worker {
+		return "", common.NewFDKError("transformations cannot be applied to a worker url")
+	}
+	if isCustomDomain {
+		obj["isCustomDomain"] = true
+	}
+	obj["transformations"] = transformations
+	return url.ObjToUrl(obj)
+}
+
+// isSignedUrl reports whether a url already carries a pixelbin signature
+func isSignedUrl(rawUrl string) bool {
+	parsed, err := neturl.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+	return parsed.Query().Get("pbs") != ""
+}
+
+// newChecksumHash returns the hash for the algorithm, defaulting to SHA256
+func newChecksumHash(algorithm ChecksumAlgorithmEnum) (hash.Hash, error) {
+	if algorithm == "" {
+		algorithm = SHA256
+	}
+	if err := algorithm.IsValid(); err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	switch algorithm {
+	case MD5:
+		return md5.New(), nil
+	case SHA1:
+		return sha1.New(), nil
+	}
+	return sha256.New(), nil
+}
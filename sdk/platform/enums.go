@@ -24,3 +24,180 @@ func (ac AccessEnum) IsValid() error {
 	}
 	return errors.New("Invalid AccessEnum type")
 }
+
+//ChecksumAlgorithmEnum used by Assets downloads
+type ChecksumAlgorithmEnum string
+
+const (
+
+	//MD5 defines constant for the `md5`
+	MD5 ChecksumAlgorithmEnum = "md5"
+
+	//SHA1 defines constant for the `sha1`
+	SHA1 ChecksumAlgorithmEnum = "sha1"
+
+	//SHA256 defines constant for the `sha256`
+	SHA256 ChecksumAlgorithmEnum = "sha256"
+)
+
+//IsValid return error if enum is invalid
+func (ch ChecksumAlgorithmEnum) IsValid() error {
+	switch ch {
+	case MD5, SHA1, SHA256:
+		return nil
+	}
+	return errors.New("Invalid ChecksumAlgorithmEnum type")
+}
+
+//BackupFormatEnum used by Assets backups
+type BackupFormatEnum string
+
+const (
+
+	//DIRECTORY defines constant for the `directory`
+	DIRECTORY BackupFormatEnum = "directory"
+
+	//TAR defines constant for the `tar`
+	TAR BackupFormatEnum = "tar"
+)
+
+//IsValid return error if enum is invalid
+func (bf BackupFormatEnum) IsValid() error {
+	switch bf {
+	case DIRECTORY, TAR:
+		return nil
+	}
+	return errors.New("Invalid BackupFormatEnum type")
+}
+
+//TransferOperationEnum used by Assets move and copy progress
+type TransferOperationEnum string
+
+const (
+
+	//MOVE defines constant for the `move`
+	MOVE TransferOperationEnum = "move"
+
+	//COPY defines constant for the `copy`
+	COPY TransferOperationEnum = "copy"
+
+	//ROLLBACK defines constant for the `rollback`
+	ROLLBACK TransferOperationEnum = "rollback"
+)
+
+//IsValid return error if enum is invalid
+func (to TransferOperationEnum) IsValid() error {
+	switch to {
+	case MOVE, COPY, ROLLBACK:
+		return nil
+	}
+	return errors.New("Invalid TransferOperationEnum type")
+}
+
+//DiffChangeEnum used by Assets diffs
+type DiffChangeEnum string
+
+const (
+
+	//ADDED defines constant for the `added`
+	ADDED DiffChangeEnum = "added"
+
+	//CHANGED defines constant for the `changed`
+	CHANGED DiffChangeEnum = "changed"
+
+	//REMOVED defines constant for the `removed`
+	REMOVED DiffChangeEnum = "removed"
+)
+
+//IsValid return error if enum is invalid
+func (dc DiffChangeEnum) IsValid() error {
+	switch dc {
+	case ADDED, CHANGED, REMOVED:
+		return nil
+	}
+	return errors.New("Invalid DiffChangeEnum type")
+}
+
+//CollisionStrategyEnum used by Assets uploads
+type CollisionStrategyEnum string
+
+const (
+
+	//FAIL defines constant for the `fail`
+	FAIL CollisionStrategyEnum = "fail"
+
+	//OVERWRITE defines constant for the `overwrite`
+	OVERWRITE CollisionStrategyEnum = "overwrite"
+
+	//AUTO_SUFFIX defines constant for the `auto-suffix`
+	AUTO_SUFFIX CollisionStrategyEnum = "auto-suffix"
+
+	//SKIP_IF_EXISTS defines constant for the `skip-if-exists`
+	SKIP_IF_EXISTS CollisionStrategyEnum = "skip-if-exists"
+
+	//VERSION_ARCHIVE defines constant for the `version-archive`
+	VERSION_ARCHIVE CollisionStrategyEnum = "version-archive"
+)
+
+//IsValid return error if enum is invalid
+func (cs CollisionStrategyEnum) IsValid() error {
+	switch cs {
+	case FAIL, OVERWRITE, AUTO_SUFFIX, SKIP_IF_EXISTS, VERSION_ARCHIVE:
+		return nil
+	}
+	return errors.New("Invalid CollisionStrategyEnum type")
+}
+
+//NamingSchemeEnum used by Assets upload policies
+type NamingSchemeEnum string
+
+const (
+
+	//SLUG defines constant for the `slug`
+	SLUG NamingSchemeEnum = "slug"
+
+	//UUID defines constant for the `uuid`
+	UUID NamingSchemeEnum = "uuid"
+
+	//CONTENT_HASH defines constant for the `content-hash`
+	CONTENT_HASH NamingSchemeEnum = "content-hash"
+)
+
+//IsValid return error if enum is invalid
+func (ns NamingSchemeEnum) IsValid() error {
+	switch ns {
+	case SLUG, UUID, CONTENT_HASH:
+		return nil
+	}
+	return errors.New("Invalid NamingSchemeEnum type")
+}
+
+//PresetActionEnum used by Assets preset plans
+type PresetActionEnum string
+
+const (
+
+	//CREATE defines constant for the `create`
+	CREATE PresetActionEnum = "create"
+
+	//UPDATE defines constant for the `update`
+	UPDATE PresetActionEnum = "update"
+
+	//ARCHIVE defines constant for the `archive`
+	ARCHIVE PresetActionEnum = "archive"
+
+	//UNARCHIVE defines constant for the `unarchive`
+	UNARCHIVE PresetActionEnum = "unarchive"
+
+	//DELETE defines constant for the `delete`
+	DELETE PresetActionEnum = "delete"
+)
+
+//IsValid return error if enum is invalid
+func (pa PresetActionEnum) IsValid() error {
+	switch pa {
+	case CREATE, UPDATE, ARCHIVE, UNARCHIVE, DELETE:
+		return nil
+	}
+	return errors.New("Invalid PresetActionEnum type")
+}
@@ -0,0 +1,55 @@
+package platform
+
+import (
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+type EnsureFolderXQuery struct {
+	// Path of the folder, every missing ancestor is created as well
+	Path string
+}
+
+/*
+summary: Create a folder and its missing ancestors
+
+description: Idempotent equivalent of `mkdir -p`. Folders that already exist are left
+untouched, including folders created by another client while EnsureFolder runs.
+The response lists the folders that were created, in order from the root.
+
+params: EnsureFolderXQuery
+*/
+func (c *Assets) EnsureFolder(
+	p EnsureFolderXQuery,
+) (*EnsureFolderResponse, error) {
+
+	result := &EnsureFolderResponse{Path: path.Normalize(p.Path), Created: []FoldersResponse{}}
+	if err := path.Validate(p.Path); err != nil {
+		return result, common.NewFDKError(err.Error())
+	}
+	parent := ""
+	for _, name := range path.Segments(p.Path) {
+		folder, err := c.findFolder(parent, name)
+		if err != nil {
+			return result, err
+		}
+		if folder == nil {
+			resp, err := c.CreateFolder(CreateFolderXQuery{Name: name, Path: parent})
+			if err != nil {
+				// another client may have created the folder in the meantime
+				if existing, findErr := c.findFolder(parent, name); findErr == nil && existing != nil {
+					parent = path.Join(parent, name)
+					continue
+				}
+				return result, err
+			}
+			var created FoldersResponse
+			if err := decodeResponse(resp, &created); err != nil {
+				return result, err
+			}
+			result.Created = append(result.Created, created)
+		}
+		parent = path.Join(parent, name)
+	}
+	return result, nil
+}
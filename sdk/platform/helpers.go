@@ -0,0 +1,164 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+)
+
+// decodeResponse converts a raw API response into one of the typed models
+func decodeResponse(resp map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	return nil
+}
+
+// defaultConcurrency is used by bulk operations when no concurrency is given
+const defaultConcurrency = 4
+
+// forEachConcurrently calls fn for every index below n using at most concurrency goroutines
+func forEachConcurrently(n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// getFile returns the typed details of a file
+func (c *Assets) getFile(fileId string) (*FilesResponse, error) {
+	resp, err := c.GetFileByFileId(GetFileByFileIdXQuery{FileId: fileId})
+	if err != nil {
+		return nil, err
+	}
+	var file FilesResponse
+	if err := decodeResponse(resp, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// patchFile sends a partial update of a file. Unlike UpdateFile it keeps empty
+// values such as an empty tag list or isActive set to false.
+func (c *Assets) patchFile(fileId string, fields map[string]interface{}) (*FilesResponse, error) {
+	apiClient := &APIClient{
+		Conf:        c.config,
+		Method:      "patch",
+		Url:         fmt.Sprintf("/service/platform/assets/v1.0/files/%s", fileId),
+		Query:       map[string]string{},
+		Body:        fields,
+		ContentType: "application/json",
+	}
+	response, err := apiClient.Execute()
+	if err != nil {
+		return nil, err
+	}
+	var file FilesResponse
+	if err := json.Unmarshal(response, &file); err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	return &file, nil
+}
+
+// patchFolder sends a partial update of a folder. Unlike UpdateFolder it can set isActive to false.
+func (c *Assets) patchFolder(folderId string, fields map[string]interface{}) (*FoldersResponse, error) {
+	apiClient := &APIClient{
+		Conf:        c.config,
+		Method:      "patch",
+		Url:         fmt.Sprintf("/service/platform/assets/v1.0/folders/%s", folderId),
+		Query:       map[string]string{},
+		Body:        fields,
+		ContentType: "application/json",
+	}
+	response, err := apiClient.Execute()
+	if err != nil {
+		return nil, err
+	}
+	var folder FoldersResponse
+	if err := json.Unmarshal(response, &folder); err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	return &folder, nil
+}
+
+// listPresets returns every preset of the organization, following the pages of GetPresets
+func (c *Assets) listPresets() ([]AddPresetResponse, error) {
+	presets := []AddPresetResponse{}
+	for pageNo := 1; ; pageNo++ {
+		apiClient := &APIClient{
+			Conf:        c.config,
+			Method:      "get",
+			Url:         "/service/platform/assets/v1.0/presets",
+			Query:       map[string]string{"pageNo": fmt.Sprintf("%d", pageNo)},
+			Body:        nil,
+			ContentType: "",
+		}
+		response, err := apiClient.Execute()
+		if err != nil {
+			return nil, err
+		}
+		var list GetPresetsResponse
+		if err := json.Unmarshal(response, &list); err != nil {
+			return nil, common.NewFDKError(err.Error())
+		}
+		presets = append(presets, list.Items...)
+		if !list.Page.HasNext || len(list.Items) == 0 {
+			return presets, nil
+		}
+	}
+}
+
+// getPreset returns the typed details of a preset
+func (c *Assets) getPreset(presetName string) (*AddPresetResponse, error) {
+	resp, err := c.GetPreset(GetPresetXQuery{PresetName: presetName})
+	if err != nil {
+		return nil, err
+	}
+	var preset AddPresetResponse
+	if err := decodeResponse(resp, &preset); err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// replacePreset replaces the definition of a preset by deleting and adding it, only the archived flag
+// can be changed in place. The previous preset is added back when the new definition is rejected.
+func (c *Assets) replacePreset(previous *AddPresetResponse, preset AddPresetXQuery) error {
+	if _, err := c.DeletePreset(DeletePresetXQuery{PresetName: previous.PresetName}); err != nil {
+		return err
+	}
+	_, err := c.AddPreset(preset)
+	if err == nil {
+		return nil
+	}
+	_, restoreErr := c.AddPreset(AddPresetXQuery{PresetName: previous.PresetName, Transformation: previous.Transformation, Params: previous.Params})
+	if restoreErr == nil && previous.Archived {
+		restoreErr = c.setPresetArchived(previous.PresetName, true)
+	}
+	if restoreErr != nil {
+		return common.NewFDKError(fmt.Sprintf("%v, the previous preset could not be restored: %v", err, restoreErr))
+	}
+	return common.NewFDKError(fmt.Sprintf("%v, the previous preset was restored", err))
+}
@@ -0,0 +1,184 @@
+package platform
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+// inventoryProgressInterval is the number of files between two progress callbacks
+const inventoryProgressInterval = 100
+
+// InventoryRootKey is the folder key of the root folder in an inventory report
+const InventoryRootKey = "/"
+
+type InventoryXQuery struct {
+	// Path of the folder to report on, empty for the whole organization
+	Path string
+	// IncludeTags fetches the details of every file to aggregate by tag
+	IncludeTags bool
+	// Concurrency bounds the number of files fetched at the same time when IncludeTags is set
+	Concurrency int
+	// Progress is called every 100 files and once the walk is done
+	Progress func(InventoryProgress)
+}
+
+/*
+summary: Build a storage inventory
+
+description: Walk the asset tree below Path and aggregate the number of files and bytes
+by folder, format and access level. Folder totals include the files of subfolders.
+Set IncludeTags to also aggregate by tag, which fetches the details of every file.
+A file is counted under each of its tags.
+
+params: InventoryXQuery
+*/
+func (c *Assets) Inventory(
+	p InventoryXQuery,
+) (*InventoryReport, error) {
+
+	root := path.Normalize(p.Path)
+	report := &InventoryReport{
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Path:        root,
+	}
+	byFolder := map[string]*InventoryBucket{}
+	byFormat := map[string]*InventoryBucket{}
+	byAccess := map[string]*InventoryBucket{}
+	byTag := map[string]*InventoryBucket{}
+	files := []ExploreItem{}
+
+	progress := func() {
+		if p.Progress != nil {
+			p.Progress(InventoryProgress{Files: report.Files, Folders: report.Folders, Bytes: report.Bytes})
+		}
+	}
+	err := c.Walk(WalkXQuery{Path: root}, func(item ExploreItem) error {
+		if item.Type == "folder" {
+			report.Folders++
+			addToBucket(byFolder, inventoryFolderKey(path.Join(item.Path, item.Name)), 0, 0)
+			return nil
+		}
+		size := int64(item.Size)
+		report.Files++
+		report.Bytes += size
+		// every ancestor up to the reported path includes the file
+		folder := path.Normalize(item.Path)
+		for {
+			addToBucket(byFolder, inventoryFolderKey(folder), 1, size)
+			if folder == root || !path.IsWithin(folder, root) {
+				break
+			}
+			folder, _ = path.Split(folder)
+		}
+		addToBucket(byFormat, item.Format, 1, size)
+		addToBucket(byAccess, string(item.Access), 1, size)
+		if p.IncludeTags {
+			files = append(files, item)
+		}
+		if report.Files%inventoryProgressInterval == 0 {
+			progress()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]*FilesResponse, len(files))
+	errs := make([]error, len(files))
+	forEachConcurrently(len(files), p.Concurrency, func(i int) {
+		details[i], errs[i] = c.getFile(files[i].FileId)
+	})
+	for i, file := range details {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		for _, tag := range file.Tags {
+			addToBucket(byTag, tag, 1, int64(files[i].Size))
+		}
+	}
+	progress()
+
+	report.ByFolder = sortedBuckets(byFolder)
+	report.ByFormat = sortedBuckets(byFormat)
+	report.ByAccess = sortedBuckets(byAccess)
+	report.ByTag = sortedBuckets(byTag)
+	return report, nil
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *InventoryReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	return nil
+}
+
+// WriteCSV writes the report as CSV rows of dimension, key, files and bytes,
+// starting with a total row
+func (r *InventoryReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{
+		{"dimension", "key", "files", "bytes"},
+		{"total", inventoryFolderKey(r.Path), strconv.Itoa(r.Files), strconv.FormatInt(r.Bytes, 10)},
+	}
+	dimensions := []struct {
+		name    string
+		buckets []InventoryBucket
+	}{
+		{"folder", r.ByFolder},
+		{"format", r.ByFormat},
+		{"access", r.ByAccess},
+		{"tag", r.ByTag},
+	}
+	for _, dimension := range dimensions {
+		for _, bucket := range dimension.buckets {
+			rows = append(rows, []string{dimension.name, bucket.Key, strconv.Itoa(bucket.Files), strconv.FormatInt(bucket.Bytes, 10)})
+		}
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return common.NewFDKError(err.Error())
+	}
+	return nil
+}
+
+func inventoryFolderKey(folderPath string) string {
+	if folderPath == "" {
+		return InventoryRootKey
+	}
+	return folderPath
+}
+
+func addToBucket(buckets map[string]*InventoryBucket, key string, files int, bytes int64) {
+	bucket, ok := buckets[key]
+	if !ok {
+		bucket = &InventoryBucket{Key: key}
+		buckets[key] = bucket
+	}
+	bucket.Files += files
+	bucket.Bytes += bytes
+}
+
+// sortedBuckets orders buckets by size, largest first
+func sortedBuckets(buckets map[string]*InventoryBucket) []InventoryBucket {
+	sorted := []InventoryBucket{}
+	for _, bucket := range buckets {
+		sorted = append(sorted, *bucket)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Bytes != sorted[j].Bytes {
+			return sorted[i].Bytes > sorted[j].Bytes
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
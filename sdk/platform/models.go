@@ -337,3 +337,389 @@ type ErrorSchema struct {
 type GetTransformationContextSuccessResponse struct {
 	Context map[string]interface{} `json:"context"`
 }
+
+// DownloadResponse used by Assets
+type DownloadResponse struct {
+	URL           string `json:"url"`
+	StatusCode    int    `json:"statusCode"`
+	ContentType   string `json:"contentType"`
+	ContentLength int64  `json:"contentLength"`
+	BytesWritten  int64  `json:"bytesWritten"`
+	ETag          string `json:"etag"`
+	LastModified  string `json:"lastModified"`
+	Checksum      string `json:"checksum"`
+	FromCache     bool   `json:"fromCache"`
+}
+
+// BackupManifest used by Assets
+type BackupManifest struct {
+	CreatedAt string   `json:"createdAt"`
+	Path      string   `json:"path"`
+	Folders   []string `json:"folders"`
+	Assets    []string `json:"assets"`
+}
+
+// BackupAsset used by Assets
+type BackupAsset struct {
+	ID       string                 `json:"_id"`
+	FileId   string                 `json:"fileId"`
+	Path     string                 `json:"path"`
+	Name     string                 `json:"name"`
+	Format   string                 `json:"format"`
+	Size     float64                `json:"size"`
+	Access   AccessEnum             `json:"access"`
+	Tags     []string               `json:"tags"`
+	Metadata map[string]interface{} `json:"metadata"`
+	Checksum string                 `json:"checksum"`
+}
+
+// BackupFailure used by Assets
+type BackupFailure struct {
+	FileId string `json:"fileId"`
+	Error  string `json:"error"`
+}
+
+// BackupProgress used by Assets
+type BackupProgress struct {
+	FileId    string `json:"fileId"`
+	Processed int    `json:"processed"`
+	Skipped   bool   `json:"skipped"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// BackupResponse used by Assets
+type BackupResponse struct {
+	Folders  int             `json:"folders"`
+	Assets   int             `json:"assets"`
+	Skipped  int             `json:"skipped"`
+	Bytes    int64           `json:"bytes"`
+	Failures []BackupFailure `json:"failures"`
+}
+
+// AssetAttributes used by Assets
+type AssetAttributes struct {
+	Access   AccessEnum             `json:"access"`
+	Tags     []string               `json:"tags"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// BulkEditResult used by Assets
+type BulkEditResult struct {
+	FileId  string          `json:"fileId"`
+	Changed bool            `json:"changed"`
+	Before  AssetAttributes `json:"before"`
+	After   AssetAttributes `json:"after"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// BulkEditResponse used by Assets
+type BulkEditResponse struct {
+	DryRun    bool             `json:"dryRun"`
+	Changed   int              `json:"changed"`
+	Unchanged int              `json:"unchanged"`
+	Failed    int              `json:"failed"`
+	Results   []BulkEditResult `json:"results"`
+}
+
+// TransferProgress used by Assets
+type TransferProgress struct {
+	Operation   TransferOperationEnum `json:"operation"`
+	Source      string                `json:"source"`
+	Destination string                `json:"destination"`
+	Completed   int                   `json:"completed"`
+	Total       int                   `json:"total"`
+}
+
+// TransferResponse used by Assets
+type TransferResponse struct {
+	Files      int      `json:"files"`
+	Folders    int      `json:"folders"`
+	RolledBack bool     `json:"rolledBack"`
+	SourceKept bool     `json:"sourceKept"`
+	Remaining  []string `json:"remaining,omitempty"`
+}
+
+// DeleteManifest used by Assets
+type DeleteManifest struct {
+	Path    string   `json:"path"`
+	Files   int      `json:"files"`
+	Folders int      `json:"folders"`
+	Bytes   int64    `json:"bytes"`
+	FileIds []string `json:"fileIds"`
+	Paths   []string `json:"paths"`
+}
+
+// RestoreManifest used by Assets
+type RestoreManifest struct {
+	CreatedAt string          `json:"createdAt"`
+	Path      string          `json:"path"`
+	Folders   []string        `json:"folders"`
+	Files     []FilesResponse `json:"files"`
+}
+
+// DeleteFailure used by Assets
+type DeleteFailure struct {
+	ID    string `json:"_id"`
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// DeleteTreeResponse used by Assets
+type DeleteTreeResponse struct {
+	DryRun   bool            `json:"dryRun"`
+	Manifest DeleteManifest  `json:"manifest"`
+	Deleted  int             `json:"deleted"`
+	Failures []DeleteFailure `json:"failures"`
+}
+
+// ArchivedFile used by Assets
+type ArchivedFile struct {
+	ID         string  `json:"_id"`
+	FileId     string  `json:"fileId"`
+	Size       float64 `json:"size"`
+	ArchivedAt string  `json:"archivedAt"`
+}
+
+// ArchiveFailure used by Assets
+type ArchiveFailure struct {
+	ID    string `json:"_id"`
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// ArchiveResponse used by Assets
+type ArchiveResponse struct {
+	Files    int              `json:"files"`
+	Folders  int              `json:"folders"`
+	Failures []ArchiveFailure `json:"failures"`
+}
+
+// PurgeArchivedResponse used by Assets
+type PurgeArchivedResponse struct {
+	DryRun   bool            `json:"dryRun"`
+	Purged   []ArchivedFile  `json:"purged"`
+	Failures []DeleteFailure `json:"failures"`
+}
+
+// EnsureFolderResponse used by Assets
+type EnsureFolderResponse struct {
+	Path    string            `json:"path"`
+	Created []FoldersResponse `json:"created"`
+}
+
+// InventoryBucket used by Assets
+type InventoryBucket struct {
+	Key   string `json:"key"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// InventoryProgress used by Assets
+type InventoryProgress struct {
+	Files   int   `json:"files"`
+	Folders int   `json:"folders"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// InventoryReport used by Assets
+type InventoryReport struct {
+	GeneratedAt string            `json:"generatedAt"`
+	Path        string            `json:"path"`
+	Files       int               `json:"files"`
+	Folders     int               `json:"folders"`
+	Bytes       int64             `json:"bytes"`
+	ByFolder    []InventoryBucket `json:"byFolder"`
+	ByFormat    []InventoryBucket `json:"byFormat"`
+	ByAccess    []InventoryBucket `json:"byAccess"`
+	ByTag       []InventoryBucket `json:"byTag"`
+}
+
+// DiffEntry used by Assets
+type DiffEntry struct {
+	Type   string         `json:"type"`
+	Change DiffChangeEnum `json:"change"`
+	Path   string         `json:"path"`
+	Fields []string       `json:"fields,omitempty"`
+}
+
+// DiffResponse used by Assets
+type DiffResponse struct {
+	Path       string      `json:"path"`
+	TargetPath string      `json:"targetPath"`
+	Added      int         `json:"added"`
+	Changed    int         `json:"changed"`
+	Removed    int         `json:"removed"`
+	Entries    []DiffEntry `json:"entries"`
+}
+
+// ApplyDiffFailure used by Assets
+type ApplyDiffFailure struct {
+	Type  string `json:"type"`
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// ApplyDiffResponse used by Assets
+type ApplyDiffResponse struct {
+	Applied  int                `json:"applied"`
+	Skipped  int                `json:"skipped"`
+	Failures []ApplyDiffFailure `json:"failures"`
+}
+
+// TagUsage used by Assets
+type TagUsage struct {
+	Tag   string `json:"tag"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// TagEditResult used by Assets
+type TagEditResult struct {
+	FileId string   `json:"fileId"`
+	Before []string `json:"before"`
+	After  []string `json:"after"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// TagEditResponse used by Assets
+type TagEditResponse struct {
+	DryRun  bool            `json:"dryRun"`
+	Changed int             `json:"changed"`
+	Failed  int             `json:"failed"`
+	Results []TagEditResult `json:"results"`
+}
+
+// PresetSpec used by Assets
+type PresetSpec struct {
+	PresetName     string                 `json:"presetName" yaml:"presetName"`
+	Transformation string                 `json:"transformation" yaml:"transformation"`
+	Params         map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+	Archived       bool                   `json:"archived,omitempty" yaml:"archived,omitempty"`
+}
+
+// PresetPlanEntry used by Assets
+type PresetPlanEntry struct {
+	PresetName string           `json:"presetName"`
+	Action     PresetActionEnum `json:"action"`
+	Fields     []string         `json:"fields,omitempty"`
+	Preset     *PresetSpec      `json:"preset,omitempty"`
+}
+
+// PresetPlan used by Assets
+type PresetPlan struct {
+	Creates    int               `json:"creates"`
+	Updates    int               `json:"updates"`
+	Archives   int               `json:"archives"`
+	Unarchives int               `json:"unarchives"`
+	Deletes    int               `json:"deletes"`
+	Entries    []PresetPlanEntry `json:"entries"`
+}
+
+// ApplyPresetsFailure used by Assets
+type ApplyPresetsFailure struct {
+	PresetName string           `json:"presetName"`
+	Action     PresetActionEnum `json:"action"`
+	Error      string           `json:"error"`
+}
+
+// ApplyPresetsResponse used by Assets
+type ApplyPresetsResponse struct {
+	DryRun   bool                  `json:"dryRun"`
+	Applied  int                   `json:"applied"`
+	Failures []ApplyPresetsFailure `json:"failures"`
+}
+
+// ExtractPresetParam used by Assets
+type ExtractPresetParam struct {
+	Name      string `json:"name"`
+	Operation int    `json:"operation"`
+	Key       string `json:"key"`
+}
+
+// ExtractPresetResponse used by Assets
+type ExtractPresetResponse struct {
+	PresetName     string                 `json:"presetName"`
+	Transformation string                 `json:"transformation"`
+	Params         map[string]interface{} `json:"params"`
+	Url            string                 `json:"url"`
+}
+
+// TransformationContextStep used by Transformation
+type TransformationContextStep struct {
+	Name              string                 `json:"name"`
+	Operation         string                 `json:"operation"`
+	Identifier        string                 `json:"identifier"`
+	Data              map[string]interface{} `json:"data"`
+	Metadata          map[string]interface{} `json:"metadata"`
+	Format            string                 `json:"format"`
+	Size              int                    `json:"size"`
+	Width             int                    `json:"width"`
+	Height            int                    `json:"height"`
+	Space             string                 `json:"space"`
+	Channels          int                    `json:"channels"`
+	Depth             string                 `json:"depth"`
+	Density           float64                `json:"density"`
+	ChromaSubsampling string                 `json:"chromaSubsampling"`
+	IsProgressive     bool                   `json:"isProgressive"`
+	ResolutionUnit    string                 `json:"resolutionUnit"`
+	HasProfile        bool                   `json:"hasProfile"`
+	HasAlpha          bool                   `json:"hasAlpha"`
+	Orientation       int                    `json:"orientation"`
+}
+
+// TransformationContextMetadata used by Transformation
+type TransformationContextMetadata struct {
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Channels     int    `json:"channels"`
+	Extension    string `json:"extension"`
+	Format       string `json:"format"`
+	ContentType  string `json:"contentType"`
+	Size         int    `json:"size"`
+	AssetType    string `json:"assetType"`
+	IsImageAsset bool   `json:"isImageAsset"`
+	IsAudioAsset bool   `json:"isAudioAsset"`
+	IsVideoAsset bool   `json:"isVideoAsset"`
+	IsRawAsset   bool   `json:"isRawAsset"`
+}
+
+// TransformationContext used by Transformation
+type TransformationContext struct {
+	Steps    []TransformationContextStep   `json:"steps"`
+	Metadata TransformationContextMetadata `json:"metadata"`
+	Headers  map[string]string             `json:"headers"`
+	Params   map[string]interface{}        `json:"params"`
+	// Raw is the context as returned by GetTransformationContext, with the fields not modelled here
+	Raw map[string]interface{} `json:"-"`
+}
+
+// TransformationContextResult used by Transformation
+type TransformationContextResult struct {
+	URL     string                 `json:"url"`
+	Context *TransformationContext `json:"context,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// CredentialParam used by Assets
+type CredentialParam struct {
+	Identifier string   `json:"identifier"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Required   bool     `json:"required"`
+	Enum       []string `json:"enum"`
+}
+
+// CredentialSchema used by Assets
+type CredentialSchema struct {
+	PluginId string            `json:"pluginId"`
+	Required bool              `json:"required"`
+	Params   []CredentialParam `json:"params"`
+}
+
+// RotateCredentialsResponse used by Assets
+type RotateCredentialsResponse struct {
+	PluginId   string `json:"pluginId"`
+	Rotated    bool   `json:"rotated"`
+	RolledBack bool   `json:"rolledBack"`
+	CheckError string `json:"checkError,omitempty"`
+}
@@ -0,0 +1,154 @@
+package platform
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+)
+
+// defaultPatchRetries is the number of times a patch is retried after a concurrent modification
+const defaultPatchRetries = 3
+
+type PatchMetadataXQuery struct {
+	FileId string
+	// Patch is deep-merged into the metadata, a nil value removes the key
+	Patch map[string]interface{}
+	// MaxRetries bounds the number of retries after a concurrent modification, nil defaults to 3
+	// and 0 disables retries
+	MaxRetries *int
+}
+
+/*
+summary: Deep-merge a patch into the metadata of a file
+
+description: Read the file, merge the patch into its metadata like a JSON merge patch and
+write the result back. Nested objects are merged key by key and a nil value removes the key,
+so workers patching different keys do not overwrite each other. The file is read again right
+before the write, and the patch is retried when it changed in the meantime.
+
+params: PatchMetadataXQuery
+*/
+func (c *Assets) PatchMetadata(
+	p PatchMetadataXQuery,
+) (*FilesResponse, error) {
+
+	return c.patchWithRetry(p.FileId, p.MaxRetries, func(file *FilesResponse) (map[string]interface{}, bool) {
+		metadata := mergeMetadata(file.Metadata, p.Patch)
+		if reflect.DeepEqual(metadata, nonNilMap(file.Metadata)) {
+			return nil, false
+		}
+		return map[string]interface{}{"metadata": metadata}, true
+	})
+}
+
+type EditTagsXQuery struct {
+	FileId string
+	Tags   []string
+	// MaxRetries bounds the number of retries after a concurrent modification, nil defaults to 3
+	// and 0 disables retries
+	MaxRetries *int
+}
+
+/*
+summary: Add tags to a file
+
+description: Add the tags the file does not have yet, keeping the tags added by other
+clients. The file is read again right before the write, and the update is retried
+when it changed in the meantime.
+
+params: EditTagsXQuery
+*/
+func (c *Assets) AddTags(
+	p EditTagsXQuery,
+) (*FilesResponse, error) {
+
+	return c.patchWithRetry(p.FileId, p.MaxRetries, func(file *FilesResponse) (map[string]interface{}, bool) {
+		tags := editAttributes(AssetAttributes{Tags: file.Tags}, BulkEditXQuery{AddTags: p.Tags}).Tags
+		return map[string]interface{}{"tags": tags}, !reflect.DeepEqual(tags, nonNilStrings(file.Tags))
+	})
+}
+
+/*
+summary: Remove tags from a file
+
+description: Remove the given tags from the file, keeping the tags added by other
+clients. The file is read again right before the write, and the update is retried
+when it changed in the meantime.
+
+params: EditTagsXQuery
+*/
+func (c *Assets) RemoveTags(
+	p EditTagsXQuery,
+) (*FilesResponse, error) {
+
+	return c.patchWithRetry(p.FileId, p.MaxRetries, func(file *FilesResponse) (map[string]interface{}, bool) {
+		tags := editAttributes(AssetAttributes{Tags: file.Tags}, BulkEditXQuery{RemoveTags: p.Tags}).Tags
+		return map[string]interface{}{"tags": tags}, !reflect.DeepEqual(tags, nonNilStrings(file.Tags))
+	})
+}
+
+// patchWithRetry reads a file, computes the fields to patch and checks that the tags and
+// metadata did not change before writing them. It starts over when they did.
+func (c *Assets) patchWithRetry(
+	fileId string,
+	retries *int,
+	edit func(file *FilesResponse) (map[string]interface{}, bool),
+) (*FilesResponse, error) {
+	maxRetries := defaultPatchRetries
+	if retries != nil && *retries >= 0 {
+		maxRetries = *retries
+	}
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		file, err := c.getFile(fileId)
+		if err != nil {
+			return nil, err
+		}
+		fields, changed := edit(file)
+		if !changed {
+			return file, nil
+		}
+		current, err := c.getFile(fileId)
+		if err != nil {
+			return nil, err
+		}
+		if !sameRevision(file, current) {
+			continue
+		}
+		return c.patchFile(fileId, fields)
+	}
+	err := common.NewFDKError(fmt.Sprintf("file %s kept being modified concurrently, gave up after %d retries", fileId, maxRetries))
+	err.Status = http.StatusConflict
+	return nil, err
+}
+
+// sameRevision reports whether two reads of a file have the same tags and metadata
+func sameRevision(a, b *FilesResponse) bool {
+	return reflect.DeepEqual(nonNilStrings(a.Tags), nonNilStrings(b.Tags)) &&
+		reflect.DeepEqual(nonNilMap(a.Metadata), nonNilMap(b.Metadata))
+}
+
+// mergeMetadata returns a copy of metadata with patch deep-merged into it
+func mergeMetadata(metadata, patch map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for key, value := range metadata {
+		merged[key] = value
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		nested, isObject := value.(map[string]interface{})
+		existing, wasObject := merged[key].(map[string]interface{})
+		if isObject && wasObject {
+			merged[key] = mergeMetadata(existing, nested)
+		} else if isObject {
+			merged[key] = mergeMetadata(nil, nested)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
@@ -0,0 +1,185 @@
+package platform
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+type ExtractPresetXQuery struct {
+	// Url is a Pixelbin url, Obj a url parsed with url.UrlToObj. One of them is required.
+	Url string
+	Obj map[string]interface{}
+	// IsCustomDomain parses Url as a url of a custom domain
+	IsCustomDomain bool
+	PresetName     string
+	// Params turn literal values of the pattern into params of the preset,
+	// their current values become the defaults
+	Params []ExtractPresetParam
+	// DryRun returns the preset and url without creating the preset
+	DryRun bool
+}
+
+/*
+summary: Create a preset from the pattern of a url
+
+description: Extract the transformation of a Pixelbin url and create a preset applying it with
+AddPreset. Each of Params replaces the value of Key in the operation at index Operation with a
+{name} placeholder, and declares the param with the replaced value as its default and the type
+of the param in the module catalogue. Values of operations missing from the catalogue stay
+strings unless they read back as the same integer, float or boolean. The response holds the url
+rewritten to use p:presetName.
+
+params: ExtractPresetXQuery
+*/
+func (c *Assets) ExtractPreset(
+	p ExtractPresetXQuery,
+) (*ExtractPresetResponse, error) {
+
+	if p.PresetName == "" {
+		return nil, common.NewFDKError("PresetName is required")
+	}
+	obj := p.Obj
+	if obj == nil {
+		if p.Url == "" {
+			return nil, common.NewFDKError("Url or Obj is required")
+		}
+		parsed, err := url.UrlToObj(p.Url, url.WithCustomDomain(p.IsCustomDomain))
+		if err != nil {
+			return nil, common.NewFDKError(err.Error())
+		}
+		obj = parsed
+	}
+	if worker, _ := obj["worker"].(bool); worker {
+		return nil, common.NewFDKError("urls of the url translation worker have no pattern to extract")
+	}
+	transformation, err := url.TransformationFromMaps(obj["transformations"])
+	if err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	if len(transformation.Operations) == 0 {
+		return nil, common.NewFDKError("url has no transformation to extract")
+	}
+
+	var catalogue *url.Catalogue
+	if len(p.Params) > 0 {
+		if catalogue, err = c.Catalogue(); err != nil {
+			return nil, err
+		}
+	}
+	params, err := extractPresetParams(transformation, p.Params, catalogue)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExtractPresetResponse{PresetName: p.PresetName, Transformation: transformation.Pattern(), Params: params}
+
+	rewritten := map[string]interface{}{}
+	for key, value := range obj {
+		rewritten[key] = value
+	}
+	rewritten["transformations"] = url.NewTransformation().Preset(p.PresetName).Maps()
+	if rewritten["cloudName"] == nil {
+		rewritten["isCustomDomain"] = true
+	}
+	result.Url, err = url.ObjToUrl(rewritten)
+	if err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+
+	if p.DryRun {
+		return result, nil
+	}
+	_, err = c.AddPreset(AddPresetXQuery{PresetName: result.PresetName, Transformation: result.Transformation, Params: result.Params})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// extractPresetParams replaces the chosen values of a transformation with placeholders and returns the param declarations
+func extractPresetParams(transformation *url.Transformation, chosen []ExtractPresetParam, catalogue *url.Catalogue) (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+	for _, param := range chosen {
+		if param.Name == "" {
+			return nil, common.NewFDKError("param name is required")
+		}
+		if _, ok := params[param.Name]; ok {
+			return nil, common.NewFDKError(fmt.Sprintf("param %s is declared more than once", param.Name))
+		}
+		if param.Operation < 0 || param.Operation >= len(transformation.Operations) {
+			return nil, common.NewFDKError(fmt.Sprintf("param %s: the url has no operation %d", param.Name, param.Operation))
+		}
+		operation := &transformation.Operations[param.Operation]
+		found := false
+		for i := range operation.Params {
+			if operation.Params[i].Key != param.Key {
+				continue
+			}
+			declaration, err := paramDeclaration(catalogue, operation, operation.Params[i])
+			if err != nil {
+				return nil, common.NewFDKError(fmt.Sprintf("param %s: %s", param.Name, err.Error()))
+			}
+			params[param.Name] = declaration
+			operation.Params[i].Value = "{" + param.Name + "}"
+			found = true
+		}
+		if !found {
+			return nil, common.NewFDKError(fmt.Sprintf("param %s: %s has no value for %s", param.Name, operation, param.Key))
+		}
+	}
+	return params, nil
+}
+
+// paramDeclaration returns the declaration of a preset param defaulting to the value of param,
+// typed like the param of the operation in the catalogue
+func paramDeclaration(catalogue *url.Catalogue, operation *url.Operation, param url.Param) (map[string]interface{}, error) {
+	operationSpec, _ := catalogue.Operation(operation.Plugin, operation.Name)
+	spec, ok := operationSpec.Param(param.Key)
+	if !ok {
+		paramType, value := literalType(param.Value)
+		return map[string]interface{}{"type": paramType, "default": value}, nil
+	}
+	declaration := map[string]interface{}{"type": spec.Type, "default": param.Value}
+	switch spec.Type {
+	case url.ParamInteger:
+		integer, err := strconv.Atoi(param.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s of %s should be an integer, got %q", param.Key, operation, param.Value)
+		}
+		declaration["default"] = integer
+	case url.ParamFloat:
+		number, err := strconv.ParseFloat(param.Value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, fmt.Errorf("%s of %s should be a finite number, got %q", param.Key, operation, param.Value)
+		}
+		declaration["default"] = number
+	case url.ParamBoolean:
+		if param.Value != "true" && param.Value != "false" {
+			return nil, fmt.Errorf("%s of %s should be true or false, got %q", param.Key, operation, param.Value)
+		}
+		declaration["default"] = param.Value == "true"
+	case url.ParamEnum:
+		declaration["enum"] = spec.Enum
+	}
+	return declaration, nil
+}
+
+// literalType returns the type of a literal value of an operation missing from the catalogue. The
+// value stays a string unless it reads back as the same text, so that 007 or nan are not turned
+// into numbers.
+func literalType(value string) (string, interface{}) {
+	if integer, err := strconv.Atoi(value); err == nil && strconv.Itoa(integer) == value {
+		return url.ParamInteger, integer
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err == nil && !math.IsNaN(number) && !math.IsInf(number, 0) && strconv.FormatFloat(number, 'f', -1, 64) == value {
+		return url.ParamFloat, number
+	}
+	if value == "true" || value == "false" {
+		return url.ParamBoolean, value == "true"
+	}
+	return url.ParamString, value
+}
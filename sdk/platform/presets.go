@@ -0,0 +1,296 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+// ParsePresetSpecsJSON reads declared presets from JSON, either a list of presets or an object
+// with a presets list. It does not read YAML, decode YAML declarations into []PresetSpec with a
+// YAML library instead.
+func ParsePresetSpecsJSON(data []byte) ([]PresetSpec, error) {
+	var presets []PresetSpec
+	if err := json.Unmarshal(data, &presets); err != nil {
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+			return nil, common.NewFDKError(err.Error())
+		}
+		var file struct {
+			Presets []PresetSpec `json:"presets"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, common.NewFDKError(err.Error())
+		}
+		presets = file.Presets
+	}
+	if err := validatePresetSpecs(presets); err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+// validatePresetSpecs checks that presets have a unique name and a parsable transformation
+func validatePresetSpecs(presets []PresetSpec) error {
+	names := map[string]bool{}
+	for _, preset := range presets {
+		if preset.PresetName == "" {
+			return common.NewFDKError("presetName is required")
+		}
+		if names[preset.PresetName] {
+			return common.NewFDKError(fmt.Sprintf("preset %s is declared more than once", preset.PresetName))
+		}
+		names[preset.PresetName] = true
+		if preset.Transformation == "" {
+			return common.NewFDKError(fmt.Sprintf("preset %s has no transformation", preset.PresetName))
+		}
+		if _, err := url.ParsePattern(preset.Transformation); err != nil {
+			return common.NewFDKError(fmt.Sprintf("preset %s: %s", preset.PresetName, err.Error()))
+		}
+	}
+	return nil
+}
+
+type PlanPresetsXQuery struct {
+	// Presets are the declared presets
+	Presets []PresetSpec
+	// Prune deletes the presets of the organization which are not declared, they are left alone otherwise
+	Prune bool
+}
+
+/*
+summary: Plan the changes reconciling presets with their declaration
+
+description: Compare the declared presets with the presets of the organization. Presets which
+do not exist yet are created, presets whose transformation or params differ are updated, and
+presets whose archived flag differs are archived or unarchived. Presets which are not declared
+are deleted when Prune is set. Nothing is changed until the plan is passed to ApplyPresets.
+
+params: PlanPresetsXQuery
+*/
+func (c *Assets) PlanPresets(
+	p PlanPresetsXQuery,
+) (*PresetPlan, error) {
+
+	if err := validatePresetSpecs(p.Presets); err != nil {
+		return nil, err
+	}
+	existing, err := c.listPresets()
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]AddPresetResponse{}
+	for _, preset := range existing {
+		current[preset.PresetName] = preset
+	}
+
+	plan := &PresetPlan{Entries: []PresetPlanEntry{}}
+	declared := map[string]bool{}
+	for i := range p.Presets {
+		preset := p.Presets[i]
+		declared[preset.PresetName] = true
+		other, ok := current[preset.PresetName]
+		if !ok {
+			plan.add(PresetPlanEntry{PresetName: preset.PresetName, Action: CREATE, Preset: &preset})
+			continue
+		}
+		fields := []string{}
+		if preset.Transformation != other.Transformation {
+			fields = append(fields, "transformation")
+		}
+		if !reflect.DeepEqual(normalizeParams(preset.Params), normalizeParams(other.Params)) {
+			fields = append(fields, "params")
+		}
+		archived := preset.Archived != other.Archived
+		switch {
+		case len(fields) > 0:
+			if archived {
+				fields = append(fields, "archived")
+			}
+			plan.add(PresetPlanEntry{PresetName: preset.PresetName, Action: UPDATE, Fields: fields, Preset: &preset})
+		case archived && preset.Archived:
+			plan.add(PresetPlanEntry{PresetName: preset.PresetName, Action: ARCHIVE, Fields: []string{"archived"}, Preset: &preset})
+		case archived:
+			plan.add(PresetPlanEntry{PresetName: preset.PresetName, Action: UNARCHIVE, Fields: []string{"archived"}, Preset: &preset})
+		}
+	}
+	if p.Prune {
+		for _, preset := range existing {
+			if !declared[preset.PresetName] {
+				plan.add(PresetPlanEntry{PresetName: preset.PresetName, Action: DELETE})
+			}
+		}
+	}
+	sort.Slice(plan.Entries, func(i, j int) bool {
+		return plan.Entries[i].PresetName < plan.Entries[j].PresetName
+	})
+	return plan, nil
+}
+
+// normalizeParams returns params as they are sent to Pixelbin, so that numbers decoded from YAML
+// as ints compare equal to the float64 numbers of JSON
+func normalizeParams(params map[string]interface{}) map[string]interface{} {
+	normalized := map[string]interface{}{}
+	data, err := json.Marshal(nonNilMap(params))
+	if err != nil || json.Unmarshal(data, &normalized) != nil {
+		return nonNilMap(params)
+	}
+	return normalized
+}
+
+// String lists the entries of the plan, one per line, prefixed with +, ~ or -
+func (p *PresetPlan) String() string {
+	var b strings.Builder
+	for _, entry := range p.Entries {
+		prefix := "~"
+		switch entry.Action {
+		case CREATE:
+			prefix = "+"
+		case DELETE:
+			prefix = "-"
+		}
+		fmt.Fprintf(&b, "%s %s %s", prefix, entry.Action, entry.PresetName)
+		if entry.Action == UPDATE {
+			fmt.Fprintf(&b, " (%s)", strings.Join(entry.Fields, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (p *PresetPlan) add(entry PresetPlanEntry) {
+	switch entry.Action {
+	case CREATE:
+		p.Creates++
+	case UPDATE:
+		p.Updates++
+	case ARCHIVE:
+		p.Archives++
+	case UNARCHIVE:
+		p.Unarchives++
+	case DELETE:
+		p.Deletes++
+	}
+	p.Entries = append(p.Entries, entry)
+}
+
+type ApplyPresetsXQuery struct {
+	// Plan is the result of PlanPresets
+	Plan *PresetPlan
+	// DryRun returns the response without changing any preset
+	DryRun bool
+}
+
+/*
+summary: Apply a preset plan
+
+description: Reconcile the presets of the organization with a plan made by PlanPresets.
+Presets are created with AddPreset, updated by deleting and adding them again since only the
+archived flag can be changed in place, with the previous definition added back when the new one
+is rejected, archived or unarchived with UpdatePreset and deleted with
+DeletePreset. Entries failing to apply are reported and the others are still applied.
+
+params: ApplyPresetsXQuery
+*/
+func (c *Assets) ApplyPresets(
+	p ApplyPresetsXQuery,
+) (*ApplyPresetsResponse, error) {
+
+	if p.Plan == nil {
+		return nil, common.NewFDKError("Plan is required")
+	}
+	result := &ApplyPresetsResponse{DryRun: p.DryRun, Failures: []ApplyPresetsFailure{}}
+	for _, entry := range p.Plan.Entries {
+		if p.DryRun {
+			result.Applied++
+			continue
+		}
+		if err := c.applyPresetEntry(entry); err != nil {
+			result.Failures = append(result.Failures, ApplyPresetsFailure{PresetName: entry.PresetName, Action: entry.Action, Error: err.Error()})
+			continue
+		}
+		result.Applied++
+	}
+	return result, nil
+}
+
+func (c *Assets) applyPresetEntry(entry PresetPlanEntry) error {
+	if entry.Action == DELETE {
+		_, err := c.DeletePreset(DeletePresetXQuery{PresetName: entry.PresetName})
+		return err
+	}
+	preset := entry.Preset
+	if preset == nil {
+		return common.NewFDKError(fmt.Sprintf("preset %s has no declaration to %s", entry.PresetName, entry.Action))
+	}
+	definition := AddPresetXQuery{PresetName: preset.PresetName, Transformation: preset.Transformation, Params: preset.Params}
+	switch entry.Action {
+	case ARCHIVE, UNARCHIVE:
+		return c.setPresetArchived(preset.PresetName, preset.Archived)
+	case UPDATE:
+		previous, err := c.getPreset(preset.PresetName)
+		if err != nil {
+			return err
+		}
+		if err := c.replacePreset(previous, definition); err != nil {
+			return err
+		}
+	default:
+		if _, err := c.AddPreset(definition); err != nil {
+			return err
+		}
+	}
+	if !preset.Archived {
+		return nil
+	}
+	return c.setPresetArchived(preset.PresetName, true)
+}
+
+// setPresetArchived sets the archived flag of a preset. UpdatePreset leaves false out of the request.
+func (c *Assets) setPresetArchived(presetName string, archived bool) error {
+	apiClient := &APIClient{
+		Conf:        c.config,
+		Method:      "patch",
+		Url:         fmt.Sprintf("/service/platform/assets/v1.0/presets/%s", presetName),
+		Query:       map[string]string{},
+		Body:        map[string]interface{}{"archived": archived},
+		ContentType: "application/json",
+	}
+	_, err := apiClient.Execute()
+	return err
+}
+
+// ResolvePreset returns the transformation and params of a preset, for url.ExpandPresets and
+// url.WithPresetExpansion. Presets are fetched with GetPreset once and cached by the client.
+// Missing presets return an error wrapping url.ErrPresetNotFound.
+func (c *Assets) ResolvePreset(name string) (*url.PresetDefinition, error) {
+	return c.presetCache.Resolve(name)
+}
+
+// ClearPresetCache drops the presets cached by ResolvePreset, presets changed through this client are dropped already
+func (c *Assets) ClearPresetCache() {
+	c.presetCache.Invalidate()
+}
+
+func (c *Assets) fetchPreset(name string) (*url.PresetDefinition, error) {
+	resp, err := c.GetPreset(GetPresetXQuery{PresetName: name})
+	if err != nil {
+		var fdkErr *common.FDKError
+		if errors.As(err, &fdkErr) && fdkErr.Status == http.StatusNotFound {
+			return nil, url.ErrPresetNotFound
+		}
+		return nil, err
+	}
+	var preset AddPresetResponse
+	if err := decodeResponse(resp, &preset); err != nil {
+		return nil, err
+	}
+	return &url.PresetDefinition{Transformation: preset.Transformation, Params: preset.Params}, nil
+}
@@ -0,0 +1,179 @@
+package platform
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+)
+
+type ListTagsXQuery struct {
+	// Path of the folder to scan, empty for the whole organization
+	Path string
+	// Concurrency bounds the number of files fetched at the same time
+	Concurrency int
+}
+
+/*
+summary: List the tags in use
+
+description: Scan every file below Path and return each distinct tag with the number of
+files and bytes using it, most used first.
+
+params: ListTagsXQuery
+*/
+func (c *Assets) ListTags(
+	p ListTagsXQuery,
+) ([]TagUsage, error) {
+
+	files, err := c.fileDetails(p.Path, p.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	usage := map[string]*TagUsage{}
+	for _, file := range files {
+		seen := map[string]bool{}
+		for _, tag := range file.Tags {
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			if usage[tag] == nil {
+				usage[tag] = &TagUsage{Tag: tag}
+			}
+			usage[tag].Files++
+			usage[tag].Bytes += int64(file.Size)
+		}
+	}
+	tags := []TagUsage{}
+	for _, tag := range usage {
+		tags = append(tags, *tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Files != tags[j].Files {
+			return tags[i].Files > tags[j].Files
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags, nil
+}
+
+type RenameTagXQuery struct {
+	// Path of the folder to update, empty for the whole organization
+	Path string
+	From string
+	To   string
+	// Concurrency bounds the number of files updated at the same time
+	Concurrency int
+	// DryRun reports the changes without updating any file
+	DryRun bool
+}
+
+/*
+summary: Rename a tag
+
+description: Replace the tag From with To on every file below Path.
+Files already tagged with To keep a single copy of it.
+
+params: RenameTagXQuery
+*/
+func (c *Assets) RenameTag(
+	p RenameTagXQuery,
+) (*TagEditResponse, error) {
+
+	return c.MergeTags(MergeTagsXQuery{
+		Path:        p.Path,
+		Tags:        []string{p.From},
+		Into:        p.To,
+		Concurrency: p.Concurrency,
+		DryRun:      p.DryRun,
+	})
+}
+
+type MergeTagsXQuery struct {
+	// Path of the folder to update, empty for the whole organization
+	Path string
+	// Tags are replaced by Into, Into itself may be one of them
+	Tags []string
+	Into string
+	// Concurrency bounds the number of files updated at the same time
+	Concurrency int
+	// DryRun reports the changes without updating any file
+	DryRun bool
+}
+
+/*
+summary: Merge tags into one
+
+description: Replace every tag of Tags with Into on the files below Path, using UpdateFile.
+The merged tag takes the position of the first replaced tag. Only files carrying one of
+the tags are updated, and a failure on one file does not stop the others.
+Set DryRun to preview the changes.
+
+params: MergeTagsXQuery
+*/
+func (c *Assets) MergeTags(
+	p MergeTagsXQuery,
+) (*TagEditResponse, error) {
+
+	if strings.TrimSpace(p.Into) == "" {
+		return nil, common.NewFDKError("the merged tag cannot be empty")
+	}
+	if len(p.Tags) == 0 {
+		return nil, common.NewFDKError("at least one tag to merge is required")
+	}
+	merged := map[string]bool{}
+	for _, tag := range p.Tags {
+		merged[tag] = true
+	}
+	files, err := c.fileDetails(p.Path, p.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TagEditResponse{DryRun: p.DryRun, Results: []TagEditResult{}}
+	for _, file := range files {
+		tags := mergeTags(file.Tags, merged, p.Into)
+		if tags != nil {
+			result.Results = append(result.Results, TagEditResult{FileId: file.FileId, Before: file.Tags, After: tags})
+		}
+	}
+	if !p.DryRun {
+		forEachConcurrently(len(result.Results), p.Concurrency, func(i int) {
+			edit := &result.Results[i]
+			if _, err := c.UpdateFile(UpdateFileXQuery{FileId: edit.FileId, Tags: edit.After}); err != nil {
+				edit.Error = err.Error()
+			}
+		})
+	}
+	for _, edit := range result.Results {
+		if edit.Error != "" {
+			result.Failed++
+		} else {
+			result.Changed++
+		}
+	}
+	return result, nil
+}
+
+// mergeTags replaces the merged tags with into, returning nil when tags do not change
+func mergeTags(tags []string, merged map[string]bool, into string) []string {
+	changed := false
+	seen := map[string]bool{}
+	result := []string{}
+	for _, tag := range tags {
+		if merged[tag] && tag != into {
+			changed = true
+			tag = into
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	if !changed {
+		return nil
+	}
+	return result
+}
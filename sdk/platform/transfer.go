@@ -0,0 +1,266 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+type MoveXQuery struct {
+	// Source is the fileId of a file or the path of a folder
+	Source string
+	// Destination is the new path and name of a file, or the new path of a folder
+	Destination string
+	// Progress is called after every file moved or restored during a rollback
+	Progress func(TransferProgress)
+}
+
+/*
+summary: Move a file or folder
+
+description: Move a file, or a folder with all its contents, to a new location.
+Missing destination folders are created. If a step fails the files moved so far
+are moved back and the created folders are deleted on a best effort basis.
+The source folder is deleted once it is listed again and found empty, files added
+to it during the move are left in place and reported in Remaining.
+
+params: MoveXQuery
+*/
+func (c *Assets) Move(
+	p MoveXQuery,
+) (*TransferResponse, error) {
+
+	return c.transfer(MOVE, p.Source, p.Destination, p.Progress, func(item ExploreItem, folderPath, name string, log *transferLog) (string, error) {
+		moved, err := c.patchFile(item.FileId, map[string]interface{}{"path": folderPath, "name": name})
+		if err != nil {
+			return "", err
+		}
+		log.moved = append(log.moved, movedFile{fileId: moved.FileId, path: item.Path, name: item.Name})
+		return moved.FileId, nil
+	})
+}
+
+type RenameXQuery struct {
+	// Source is the fileId of a file or the path of a folder
+	Source string
+	// Name is the new name, the file or folder stays in the same parent folder
+	Name     string
+	Progress func(TransferProgress)
+}
+
+/*
+summary: Rename a file or folder
+
+description: Rename a file or folder in place. Renaming a folder moves all its contents.
+
+params: RenameXQuery
+*/
+func (c *Assets) Rename(
+	p RenameXQuery,
+) (*TransferResponse, error) {
+
+	if err := path.ValidateName(p.Name); err != nil {
+		return nil, common.NewFDKError(err.Error())
+	}
+	parent, _ := path.Split(p.Source)
+	return c.Move(MoveXQuery{Source: p.Source, Destination: path.Join(parent, p.Name), Progress: p.Progress})
+}
+
+type CopyXQuery struct {
+	// Source is the fileId of a file or the path of a folder
+	Source string
+	// Destination is the path and name of the copied file, or the path of the copied folder
+	Destination string
+	// Overwrite replaces existing files, these cannot be restored by a rollback
+	Overwrite bool
+
+	// SignToken and SignTokenID are used to fetch private assets
+	SignTokenID int
+	SignToken   string
+
+	// Progress is called after every file copied or deleted during a rollback
+	Progress func(TransferProgress)
+}
+
+/*
+summary: Copy a file or folder
+
+description: Copy a file, or a folder with all its contents, to a new location.
+Originals are re-ingested with UrlUpload from their CDN url, keeping access, tags and metadata.
+If a step fails the copies made so far and the created folders are deleted on a best effort basis.
+
+params: CopyXQuery
+*/
+func (c *Assets) Copy(
+	p CopyXQuery,
+) (*TransferResponse, error) {
+
+	return c.transfer(COPY, p.Source, p.Destination, p.Progress, func(item ExploreItem, folderPath, name string, log *transferLog) (string, error) {
+		file, err := c.getFile(item.FileId)
+		if err != nil {
+			return "", err
+		}
+		sourceUrl, err := c.resolveDownloadUrl(DownloadXQuery{File: file, SignToken: p.SignToken, SignTokenID: p.SignTokenID})
+		if err != nil {
+			return "", err
+		}
+		resp, err := c.UrlUpload(UrlUploadXQuery{
+			URL:        sourceUrl,
+			Path:       folderPath,
+			Name:       name,
+			Access:     file.Access,
+			Tags:       file.Tags,
+			Metadata:   file.Metadata,
+			Overwrite:  p.Overwrite,
+			skipPolicy: true,
+		})
+		if err != nil {
+			return "", err
+		}
+		var copied FilesResponse
+		if err := decodeResponse(resp, &copied); err != nil {
+			return "", err
+		}
+		log.copied = append(log.copied, copied.FileId)
+		return copied.FileId, nil
+	})
+}
+
+// transferStep moves or copies a single file and records it in the log
+type transferStep func(item ExploreItem, folderPath, name string, log *transferLog) (string, error)
+
+// transferLog records the completed steps of a move or copy so they can be undone
+type transferLog struct {
+	created []FoldersResponse
+	moved   []movedFile
+	copied  []string
+}
+
+type movedFile struct {
+	fileId string
+	path   string
+	name   string
+}
+
+func (c *Assets) transfer(operation TransferOperationEnum, source, destination string, progress func(TransferProgress), step transferStep) (*TransferResponse, error) {
+	destination = path.Normalize(destination)
+	if destination == "" {
+		return nil, common.NewFDKError("Destination is required")
+	}
+	src, err := c.resolveTree(source)
+	if err != nil {
+		return nil, err
+	}
+	if src.folder != nil && path.IsWithin(destination, src.path) {
+		return nil, common.NewFDKError("Destination cannot be inside the source folder")
+	}
+
+	result := &TransferResponse{}
+	log := &transferLog{}
+	fail := func(err error) (*TransferResponse, error) {
+		rollbackErr := c.rollbackTransfer(log, progress)
+		result.RolledBack = rollbackErr == nil
+		if rollbackErr != nil {
+			return result, common.NewFDKError(fmt.Sprintf("%s failed: %v, rollback incomplete: %v", operation, err, rollbackErr))
+		}
+		return result, common.NewFDKError(fmt.Sprintf("%s failed: %v", operation, err))
+	}
+
+	targetFolder, targetName := destination, ""
+	if src.folder == nil {
+		targetFolder, targetName = path.Split(strings.TrimSuffix(destination, "."+src.files[0].Format))
+	}
+	ensured, err := c.EnsureFolder(EnsureFolderXQuery{Path: targetFolder})
+	log.created = append(log.created, ensured.Created...)
+	if err != nil {
+		return fail(err)
+	}
+	for _, folder := range src.folders {
+		ensured, err := c.EnsureFolder(EnsureFolderXQuery{Path: path.Rebase(path.Join(folder.Path, folder.Name), src.path, destination)})
+		log.created = append(log.created, ensured.Created...)
+		if err != nil {
+			return fail(err)
+		}
+		result.Folders++
+	}
+
+	for i, item := range src.files {
+		folderPath, name := targetFolder, targetName
+		if src.folder != nil {
+			folderPath, name = path.Rebase(item.Path, src.path, destination), item.Name
+		}
+		fileId, err := step(item, folderPath, name, log)
+		if err != nil {
+			return fail(fmt.Errorf("%s: %v", item.FileId, err))
+		}
+		result.Files++
+		if progress != nil {
+			progress(TransferProgress{Operation: operation, Source: item.FileId, Destination: fileId, Completed: i + 1, Total: len(src.files)})
+		}
+	}
+
+	if operation == MOVE && src.folder != nil {
+		// deleting a folder deletes its contents, it is kept when files were added during the move
+		remaining, err := c.resolveTree(src.path)
+		if err != nil {
+			return fail(err)
+		}
+		if len(remaining.files) > 0 {
+			result.SourceKept = true
+			for _, item := range remaining.files {
+				result.Remaining = append(result.Remaining, item.FileId)
+			}
+			return result, nil
+		}
+		if _, err := c.DeleteFolder(DeleteFolderXQuery{ID: src.folder.ID}); err != nil {
+			return fail(err)
+		}
+	}
+	return result, nil
+}
+
+// rollbackTransfer undoes the steps in log in reverse order, continuing past failures
+func (c *Assets) rollbackTransfer(log *transferLog, progress func(TransferProgress)) error {
+	failures := []string{}
+	total := len(log.moved) + len(log.copied)
+	completed := 0
+	report := func(source, destination string) {
+		completed++
+		if progress != nil {
+			progress(TransferProgress{Operation: ROLLBACK, Source: source, Destination: destination, Completed: completed, Total: total})
+		}
+	}
+	for i := len(log.moved) - 1; i >= 0; i-- {
+		moved := log.moved[i]
+		restored, err := c.patchFile(moved.fileId, map[string]interface{}{"path": moved.path, "name": moved.name})
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", moved.fileId, err))
+			continue
+		}
+		report(moved.fileId, restored.FileId)
+	}
+	for i := len(log.copied) - 1; i >= 0; i-- {
+		if _, err := c.DeleteFile(DeleteFileXQuery{FileId: log.copied[i]}); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", log.copied[i], err))
+			continue
+		}
+		report(log.copied[i], "")
+	}
+	if len(failures) > 0 {
+		// deleting a folder deletes its contents, keep them while files could not be restored
+		return errors.New(strings.Join(failures, "; "))
+	}
+	for i := len(log.created) - 1; i >= 0; i-- {
+		folder := log.created[i]
+		if _, err := c.DeleteFolder(DeleteFolderXQuery{ID: folder.ID}); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", path.Join(folder.Path, folder.Name), err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package platform
+
+import (
+	"strings"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+)
+
+/*
+summary: Get the typed transformation context of a url
+
+description: Get the context of GetTransformationContext decoded into TransformationContext, with
+the steps applied to the asset, the metadata of the original asset, the request headers and the
+params. The context as returned by the API is kept in Raw.
+
+params: GetTransformationContextXQuery
+*/
+func (c *Transformation) GetContext(
+	p GetTransformationContextXQuery,
+) (*TransformationContext, error) {
+
+	resp, err := c.GetTransformationContext(p)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := resp["context"].(map[string]interface{})
+	if !ok {
+		return nil, common.NewFDKError("response has no transformation context")
+	}
+	context := &TransformationContext{}
+	if err := decodeResponse(raw, context); err != nil {
+		return nil, err
+	}
+	context.Raw = raw
+	return context, nil
+}
+
+// LastStep returns the last step applied, which describes the asset served by the url
+func (c *TransformationContext) LastStep() (TransformationContextStep, bool) {
+	if len(c.Steps) == 0 {
+		return TransformationContextStep{}, false
+	}
+	return c.Steps[len(c.Steps)-1], true
+}
+
+// Step returns the first step of an operation, such as Step("t", "resize")
+func (c *TransformationContext) Step(identifier, name string) (TransformationContextStep, bool) {
+	for _, step := range c.Steps {
+		if step.Identifier == identifier && step.Name == name {
+			return step, true
+		}
+	}
+	return TransformationContextStep{}, false
+}
+
+// Header returns a request header of the context regardless of the case of its name
+func (c *TransformationContext) Header(name string) string {
+	for key, value := range c.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+type GetTransformationContextsXQuery struct {
+	// URLs are the urls to get the context of, each distinct url is fetched once
+	URLs []string
+	// Concurrency bounds the number of contexts fetched at the same time
+	Concurrency int
+}
+
+/*
+summary: Get the typed transformation contexts of many urls
+
+description: Get the context of every url with GetContext concurrently. Urls given more than once
+are fetched once and share their result. Results are in the order of URLs, a url whose context
+cannot be fetched has its error set and does not stop the others.
+
+params: GetTransformationContextsXQuery
+*/
+func (c *Transformation) GetContexts(
+	p GetTransformationContextsXQuery,
+) ([]TransformationContextResult, error) {
+
+	distinct := []string{}
+	indexes := map[string]int{}
+	for _, url := range p.URLs {
+		if url == "" {
+			return nil, common.NewFDKError("URLs cannot contain an empty url")
+		}
+		if _, ok := indexes[url]; !ok {
+			indexes[url] = len(distinct)
+			distinct = append(distinct, url)
+		}
+	}
+	fetched := make([]TransformationContextResult, len(distinct))
+	forEachConcurrently(len(distinct), p.Concurrency, func(i int) {
+		fetched[i].URL = distinct[i]
+		context, err := c.GetContext(GetTransformationContextXQuery{URL: distinct[i]})
+		if err != nil {
+			fetched[i].Error = err.Error()
+			return
+		}
+		fetched[i].Context = context
+	})
+	results := make([]TransformationContextResult, len(p.URLs))
+	for i, url := range p.URLs {
+		results[i] = fetched[indexes[url]]
+	}
+	return results, nil
+}
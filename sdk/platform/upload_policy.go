@@ -0,0 +1,227 @@
+package platform
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+// contentHashLength is the number of hex characters of the sha256 used by the CONTENT_HASH naming scheme
+const contentHashLength = 32
+
+var templatePattern = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// UploadPolicy holds the defaults applied to every upload of a client.
+// Values set on an upload request take precedence over the policy.
+//
+// Path and string Metadata values are templates which can use {yyyy}, {mm}, {dd}, {hh},
+// {date}, {timestamp}, {uploader}, {source}, {name} and {format}.
+type UploadPolicy struct {
+	// Access is used when the upload has no access
+	Access AccessEnum
+	// Tags are added to the tags of the upload
+	Tags []string
+	// Metadata is used for the keys missing from the metadata of the upload
+	Metadata map[string]interface{}
+	// Path is the folder template used when the upload has no path, such as `uploads/{yyyy}/{mm}`
+	Path string
+	// Naming names uploads without a name, the name of the source file is kept when empty
+	Naming NamingSchemeEnum
+	// Uploader is the value of the {uploader} placeholder
+	Uploader string
+}
+
+// SetUploadPolicy applies a policy to FileUpload, UrlUpload, CreateSignedUrl and CreateSignedUrlV2,
+// pass nil to remove it. Copy, Restore and ApplyDiff keep the attributes of the assets they re-upload.
+func (c *Assets) SetUploadPolicy(policy *UploadPolicy) error {
+	if policy != nil {
+		if err := policy.validate(); err != nil {
+			return common.NewFDKError(err.Error())
+		}
+	}
+	c.uploadPolicy = policy
+	return nil
+}
+
+func (u *UploadPolicy) validate() error {
+	if u.Access != "" {
+		if err := u.Access.IsValid(); err != nil {
+			return err
+		}
+	}
+	if u.Naming != "" {
+		if err := u.Naming.IsValid(); err != nil {
+			return err
+		}
+	}
+	templates := []string{u.Path}
+	for _, value := range u.Metadata {
+		if template, ok := value.(string); ok {
+			templates = append(templates, template)
+		}
+	}
+	for _, template := range templates {
+		if _, err := renderTemplate(template, templateValues(time.Now(), "", "", "", "")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// policyUpload points to the fields of an upload request an UploadPolicy fills in
+type policyUpload struct {
+	path     *string
+	name     *string
+	access   *AccessEnum
+	tags     *[]string
+	metadata *map[string]interface{}
+	// source is the file name or url the upload comes from, fileName its name with the extension
+	source   string
+	fileName string
+	// file is hashed by the CONTENT_HASH naming scheme
+	file *os.File
+	// url is fetched and hashed by the CONTENT_HASH naming scheme for url uploads
+	url string
+	// skip leaves the upload unchanged
+	skip bool
+}
+
+// applyUploadPolicy fills in an upload request with the defaults of the upload policy
+func (c *Assets) applyUploadPolicy(u policyUpload) error {
+	policy := c.uploadPolicy
+	if policy == nil || u.skip {
+		return nil
+	}
+	if *u.access == "" {
+		*u.access = policy.Access
+	}
+	if len(policy.Tags) > 0 {
+		*u.tags = editAttributes(AssetAttributes{Tags: policy.Tags}, BulkEditXQuery{AddTags: *u.tags}).Tags
+	}
+
+	if *u.name == "" && policy.Naming != "" {
+		name, err := policyName(policy.Naming, u)
+		if err != nil {
+			return err
+		}
+		*u.name = name
+	}
+	name, format := uploadName(*u.name, u.fileName)
+	values := templateValues(time.Now(), policy.Uploader, u.source, name, format)
+
+	if *u.path == "" && policy.Path != "" {
+		folderPath, err := renderTemplate(policy.Path, values)
+		if err != nil {
+			return common.NewFDKError(err.Error())
+		}
+		*u.path = folderPath
+	}
+	if len(policy.Metadata) > 0 {
+		metadata := map[string]interface{}{}
+		for key, value := range policy.Metadata {
+			if template, ok := value.(string); ok {
+				rendered, err := renderTemplate(template, values)
+				if err != nil {
+					return common.NewFDKError(err.Error())
+				}
+				value = rendered
+			}
+			metadata[key] = value
+		}
+		for key, value := range *u.metadata {
+			metadata[key] = value
+		}
+		*u.metadata = metadata
+	}
+	return nil
+}
+
+// policyName names an upload with a naming scheme
+func policyName(naming NamingSchemeEnum, u policyUpload) (string, error) {
+	switch naming {
+	case SLUG:
+		name, _ := uploadName("", u.fileName)
+		return path.Slug(name), nil
+	case UUID:
+		return newUUID()
+	case CONTENT_HASH:
+		hash := sha256.New()
+		switch {
+		case u.file != nil:
+			if _, err := io.Copy(hash, u.file); err != nil {
+				return "", common.NewFDKError(err.Error())
+			}
+			if _, err := u.file.Seek(0, io.SeekStart); err != nil {
+				return "", common.NewFDKError(err.Error())
+			}
+		case u.url != "":
+			res, err := common.HttpStream("GET", u.url, nil)
+			if err != nil {
+				return "", err
+			}
+			defer res.Body.Close()
+			if _, err := io.Copy(hash, res.Body); err != nil {
+				return "", common.NewFDKError(err.Error())
+			}
+		default:
+			// signed urls are uploaded to later, there is no content to hash yet
+			return "", common.NewFDKError("the content-hash naming scheme needs the content of the upload, give signed uploads a Name")
+		}
+		return hex.EncodeToString(hash.Sum(nil))[:contentHashLength], nil
+	}
+	return "", nil
+}
+
+// signedUploadFileName returns the file name of a signed upload, which has no source file
+func signedUploadFileName(name, format string) string {
+	return path.FileId("", name, format)
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", common.NewFDKError(err.Error())
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+func templateValues(now time.Time, uploader, source, name, format string) map[string]string {
+	now = now.UTC()
+	return map[string]string{
+		"yyyy":      now.Format("2006"),
+		"mm":        now.Format("01"),
+		"dd":        now.Format("02"),
+		"hh":        now.Format("15"),
+		"date":      now.Format("2006-01-02"),
+		"timestamp": now.Format(time.RFC3339),
+		"uploader":  uploader,
+		"source":    source,
+		"name":      name,
+		"format":    format,
+	}
+}
+
+// renderTemplate replaces the {placeholders} of a template, unknown placeholders are an error
+func renderTemplate(template string, values map[string]string) (string, error) {
+	var err error
+	rendered := templatePattern.ReplaceAllStringFunc(template, func(match string) string {
+		value, ok := values[strings.Trim(match, "{}")]
+		if !ok && err == nil {
+			err = fmt.Errorf("unknown placeholder %s in template %q", match, template)
+		}
+		return value
+	})
+	return rendered, err
+}
@@ -0,0 +1,238 @@
+package platform
+
+import (
+	"errors"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+// defaultWalkPageSize is the ListFiles page size used while walking the tree
+const defaultWalkPageSize = 100
+
+// ExploreItem is a file or folder returned by ListFiles
+type ExploreItem = exploreItem
+
+// SkipFolder can be returned by a WalkFunc to skip the contents of a folder
+var SkipFolder = errors.New("skip this folder")
+
+// WalkFunc is called for every file and folder found while walking the tree.
+// Folder items always carry the path of their parent folder.
+type WalkFunc func(item ExploreItem) error
+
+type WalkXQuery struct {
+	// Path of the folder to walk, empty for the root folder
+	Path     string
+	PageSize float64
+}
+
+/*
+summary: Walk files and folders
+
+description: Walk the asset tree below a folder using ListFiles, calling fn for
+every file and folder. Folders are visited before their contents.
+Return SkipFolder from fn to skip the contents of a folder, any other error stops the walk.
+
+params: WalkXQuery
+*/
+func (c *Assets) Walk(
+	p WalkXQuery,
+	fn WalkFunc,
+) error {
+
+	pageSize := p.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultWalkPageSize
+	}
+	return c.walkFolder(path.Normalize(p.Path), pageSize, fn)
+}
+
+func (c *Assets) walkFolder(folderPath string, pageSize float64, fn WalkFunc) error {
+	for pageNo := float64(1); ; pageNo++ {
+		resp, err := c.ListFiles(ListFilesXQuery{
+			Path:     folderPath,
+			PageNo:   pageNo,
+			PageSize: pageSize,
+		})
+		if err != nil {
+			return err
+		}
+		var list ListFilesResponse
+		if err := decodeResponse(resp, &list); err != nil {
+			return err
+		}
+		for _, item := range list.Items {
+			if item.Type != "folder" {
+				if err := fn(item); err != nil {
+					return err
+				}
+				continue
+			}
+			if item.Path == "" {
+				item.Path = folderPath
+			}
+			err := fn(item)
+			if err == SkipFolder {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := c.walkFolder(path.Join(item.Path, item.Name), pageSize, fn); err != nil {
+				return err
+			}
+		}
+		if !list.Page.HasNext || len(list.Items) == 0 {
+			return nil
+		}
+	}
+}
+
+// findFolder returns the folder with the given name inside folderPath, nil if there is none
+func (c *Assets) findFolder(folderPath, name string) (*ExploreItem, error) {
+	resp, err := c.ListFiles(ListFilesXQuery{
+		Path:        folderPath,
+		Name:        name,
+		OnlyFolders: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var list ListFilesResponse
+	if err := decodeResponse(resp, &list); err != nil {
+		return nil, err
+	}
+	for _, item := range list.Items {
+		if item.Type == "folder" && item.Name == name && (item.Path == "" || path.Normalize(item.Path) == path.Normalize(folderPath)) {
+			return &item, nil
+		}
+	}
+	return nil, nil
+}
+
+// findFile returns the file named name in folderPath, with any format when format is empty
+func (c *Assets) findFile(folderPath, name, format string) (*ExploreItem, error) {
+	resp, err := c.ListFiles(ListFilesXQuery{
+		Path:      folderPath,
+		Name:      name,
+		OnlyFiles: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var list ListFilesResponse
+	if err := decodeResponse(resp, &list); err != nil {
+		return nil, err
+	}
+	for _, item := range list.Items {
+		if item.Type != "folder" && item.Name == name && (format == "" || item.Format == format) &&
+			path.Normalize(item.Path) == path.Normalize(folderPath) {
+			return &item, nil
+		}
+	}
+	return nil, nil
+}
+
+// assetTree is a single file, or a folder with every file and folder below it
+type assetTree struct {
+	path    string
+	folder  *ExploreItem
+	folders []ExploreItem
+	files   []ExploreItem
+}
+
+// resolveTree finds out whether source is a folder or the fileId of a file and lists its contents
+func (c *Assets) resolveTree(source string) (*assetTree, error) {
+	source = path.Normalize(source)
+	if source == "" {
+		return nil, common.NewFDKError("Source is required")
+	}
+	parent, name := path.Split(source)
+	folder, err := c.findFolder(parent, name)
+	if err != nil {
+		return nil, err
+	}
+	if folder == nil {
+		file, err := c.getFile(source)
+		if err != nil {
+			return nil, err
+		}
+		return &assetTree{path: source, files: []ExploreItem{{
+			ID:     file.ID,
+			Name:   file.Name,
+			Type:   "file",
+			Path:   file.Path,
+			FileId: file.FileId,
+			Format: file.Format,
+			Size:   file.Size,
+			Access: file.Access,
+		}}}, nil
+	}
+
+	tree := &assetTree{path: source, folder: folder}
+	err = c.Walk(WalkXQuery{Path: source}, func(item ExploreItem) error {
+		if item.Type == "folder" {
+			tree.folders = append(tree.folders, item)
+		} else {
+			tree.files = append(tree.files, item)
+		}
+		return nil
+	})
+	return tree, err
+}
+
+// selectFiles returns the fileIds matched by a ListFiles query, or of every file below folderPath
+func (c *Assets) selectFiles(query *ListFilesXQuery, folderPath string) ([]string, error) {
+	fileIds := []string{}
+	if query == nil {
+		err := c.Walk(WalkXQuery{Path: folderPath}, func(item ExploreItem) error {
+			if item.Type != "folder" {
+				fileIds = append(fileIds, item.FileId)
+			}
+			return nil
+		})
+		return fileIds, err
+	}
+	q := *query
+	q.OnlyFiles = true
+	if q.PageSize <= 0 {
+		q.PageSize = defaultWalkPageSize
+	}
+	for q.PageNo = 1; ; q.PageNo++ {
+		resp, err := c.ListFiles(q)
+		if err != nil {
+			return nil, err
+		}
+		var list ListFilesResponse
+		if err := decodeResponse(resp, &list); err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			if item.Type != "folder" {
+				fileIds = append(fileIds, item.FileId)
+			}
+		}
+		if !list.Page.HasNext || len(list.Items) == 0 {
+			return fileIds, nil
+		}
+	}
+}
+
+// fileDetails fetches the details of every file below folderPath, in walk order
+func (c *Assets) fileDetails(folderPath string, concurrency int) ([]*FilesResponse, error) {
+	fileIds, err := c.selectFiles(nil, folderPath)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*FilesResponse, len(fileIds))
+	errs := make([]error, len(fileIds))
+	forEachConcurrently(len(fileIds), concurrency, func(i int) {
+		files[i], errs[i] = c.getFile(fileIds[i])
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,297 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// signatureParams are added by security.SignURL and change on every signing
+var signatureParams = []string{"pbs", "pbe", "pbt"}
+
+const (
+	dataSuffix = ".data"
+	metaSuffix = ".json"
+)
+
+// Entry describes a cached response
+type Entry struct {
+	Key          string    `json:"key"`
+	ETag         string    `json:"etag"`
+	LastModified string    `json:"lastModified"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	ValidatedAt  time.Time `json:"validatedAt"`
+	LastAccess   time.Time `json:"lastAccess"`
+}
+
+// DiskCache is a size bounded LRU cache of downloaded content stored in a directory.
+// Entries older than the TTL have to be revalidated with the origin before they are served.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+// NewDiskCache opens the cache in dir, picking up entries stored by a previous process
+func NewDiskCache(dir string, maxBytes int64, ttl time.Duration) (*DiskCache, error) {
+	if maxBytes <= 0 {
+		return nil, errors.New("maxBytes should be greater than 0")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	c := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		lru:      list.New(),
+		entries:  map[string]*list.Element{},
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+	return c, nil
+}
+
+// CanonicalKey returns the cache key of a pixelbin url. Signature query params are
+// dropped and the remaining ones sorted so that re-signed urls share an entry.
+func CanonicalKey(rawUrl string) (string, error) {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	for _, param := range signatureParams {
+		query.Del(param)
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.RawQuery = query.Encode()
+	parsed.Fragment = ""
+	return parsed.String(), nil
+}
+
+// Get returns the entry stored for key
+func (c *DiskCache) Get(key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return Entry{}, false
+	}
+	return *element.Value.(*Entry), true
+}
+
+// Fresh reports whether an entry can be served without revalidation
+func (c *DiskCache) Fresh(entry Entry) bool {
+	return time.Since(entry.ValidatedAt) < c.ttl
+}
+
+// Open returns the content stored for key and marks it as recently used
+func (c *DiskCache) Open(key string) (io.ReadCloser, Entry, error) {
+	c.mu.Lock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil, Entry{}, os.ErrNotExist
+	}
+	entry := element.Value.(*Entry)
+	entry.LastAccess = time.Now()
+	c.lru.MoveToFront(element)
+	snapshot := *entry
+	c.mu.Unlock()
+
+	file, err := os.Open(c.path(key, dataSuffix))
+	if err != nil {
+		c.Remove(key)
+		return nil, Entry{}, err
+	}
+	return file, snapshot, nil
+}
+
+// Revalidated marks an entry as confirmed by the origin, restarting its TTL
+func (c *DiskCache) Revalidated(key string) error {
+	c.mu.Lock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return os.ErrNotExist
+	}
+	entry := element.Value.(*Entry)
+	entry.ValidatedAt = time.Now()
+	snapshot := *entry
+	c.mu.Unlock()
+	return c.writeMeta(snapshot)
+}
+
+// Remove drops the entry stored for key
+func (c *DiskCache) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+// Size returns the number of content bytes held by the cache
+func (c *DiskCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Len returns the number of entries held by the cache
+func (c *DiskCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// NewWriter starts storing new content for key. The entry only replaces an
+// existing one once Commit is called.
+func (c *DiskCache) NewWriter(key string) (*EntryWriter, error) {
+	file, err := os.CreateTemp(c.dir, "pending-*")
+	if err != nil {
+		return nil, err
+	}
+	return &EntryWriter{cache: c, key: key, file: file}, nil
+}
+
+// EntryWriter receives the content of a new cache entry
+type EntryWriter struct {
+	cache *DiskCache
+	key   string
+	file  *os.File
+	size  int64
+}
+
+func (w *EntryWriter) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Commit stores the written content along with the validators in entry
+func (w *EntryWriter) Commit(entry Entry) error {
+	if err := w.file.Close(); err != nil {
+		os.Remove(w.file.Name())
+		return err
+	}
+	c := w.cache
+	now := time.Now()
+	entry.Key = w.key
+	entry.Size = w.size
+	entry.ValidatedAt = now
+	entry.LastAccess = now
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[w.key]; ok {
+		c.removeElement(element)
+	}
+	if err := os.Rename(w.file.Name(), c.path(w.key, dataSuffix)); err != nil {
+		os.Remove(w.file.Name())
+		return err
+	}
+	if err := c.writeMeta(entry); err != nil {
+		os.Remove(c.path(w.key, dataSuffix))
+		return err
+	}
+	c.entries[w.key] = c.lru.PushFront(&entry)
+	c.size += entry.Size
+	c.evict()
+	return nil
+}
+
+// Abort discards the written content
+func (w *EntryWriter) Abort() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
+
+func (c *DiskCache) path(key, suffix string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+suffix)
+}
+
+func (c *DiskCache) writeMeta(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(entry.Key, metaSuffix), data, 0o644)
+}
+
+// evict removes least recently used entries until the cache fits in maxBytes
+func (c *DiskCache) evict() {
+	for c.size > c.maxBytes && c.lru.Len() > 0 {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+func (c *DiskCache) removeElement(element *list.Element) {
+	entry := element.Value.(*Entry)
+	c.lru.Remove(element)
+	delete(c.entries, entry.Key)
+	c.size -= entry.Size
+	os.Remove(c.path(entry.Key, dataSuffix))
+	os.Remove(c.path(entry.Key, metaSuffix))
+}
+
+// load reads the entries left in the directory by a previous process
+func (c *DiskCache) load() error {
+	pending, _ := filepath.Glob(filepath.Join(c.dir, "pending-*"))
+	for _, file := range pending {
+		os.Remove(file)
+	}
+	metaFiles, err := filepath.Glob(filepath.Join(c.dir, "*"+metaSuffix))
+	if err != nil {
+		return err
+	}
+	loaded := []*Entry{}
+	for _, metaFile := range metaFiles {
+		data, err := os.ReadFile(metaFile)
+		if err != nil {
+			continue
+		}
+		entry := &Entry{}
+		if json.Unmarshal(data, entry) != nil || c.path(entry.Key, metaSuffix) != metaFile {
+			os.Remove(metaFile)
+			continue
+		}
+		info, err := os.Stat(c.path(entry.Key, dataSuffix))
+		if err != nil || info.Size() != entry.Size {
+			os.Remove(metaFile)
+			os.Remove(c.path(entry.Key, dataSuffix))
+			continue
+		}
+		loaded = append(loaded, entry)
+	}
+	sort.Slice(loaded, func(i, j int) bool {
+		return loaded[i].LastAccess.After(loaded[j].LastAccess)
+	})
+	for _, entry := range loaded {
+		c.entries[entry.Key] = c.lru.PushBack(entry)
+		c.size += entry.Size
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+// Package codegen generates a Go package with one typed constructor per operation of the module catalogue
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+// DefaultPackage is the name of the generated package when none is given
+const DefaultPackage = "operations"
+
+const urlImport = "github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+
+// ptrFunc is the generated helper returning a pointer to a number or boolean
+const ptrFunc = "Ptr"
+
+// Generate returns the formatted source of a package with, for every operation of the catalogue,
+// a params struct, a function returning the params set to their defaults, a constructor returning
+// the url.Operation, and a string type with constants for every enum param. Number and boolean
+// params are pointers, nil when not set, so that 0 and false can be passed.
+func Generate(catalogue *url.Catalogue, packageName string) ([]byte, error) {
+	if catalogue == nil || len(catalogue.Plugins) == 0 {
+		return nil, errors.New("catalogue has no plugins")
+	}
+	if packageName == "" {
+		packageName = DefaultPackage
+	}
+	g := &generator{names: map[string]bool{ptrFunc: true}}
+	for _, id := range catalogue.PluginIds() {
+		for _, operation := range catalogue.Plugins[id].Operations {
+			g.operation(id, operation)
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by pixelbin-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "// Package %s has typed constructors for the operations of the Pixelbin module catalogue\n", packageName)
+	fmt.Fprintf(&src, "package %s\n\nimport (\n", packageName)
+	if g.usesFmt {
+		fmt.Fprintf(&src, "\t\"fmt\"\n\n")
+	}
+	fmt.Fprintf(&src, "\t%q\n)\n", urlImport)
+	if g.usesPtr {
+		fmt.Fprintf(&src, "\n// %s returns a pointer to v, to set the number and boolean params\n", ptrFunc)
+		fmt.Fprintf(&src, "func %s[T any](v T) *T {\nreturn &v\n}\n", ptrFunc)
+	}
+	src.Write(g.body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid source: %w", err)
+	}
+	return formatted, nil
+}
+
+type generator struct {
+	body    bytes.Buffer
+	names   map[string]bool
+	usesFmt bool
+	usesPtr bool
+}
+
+// field is a param of an operation with the Go names generated for it
+type field struct {
+	url.ParamSpec
+	name     string
+	goType   string
+	defValue string
+	consts   []enumConst
+}
+
+// optional reports whether the field is a pointer, nil when the param is not set
+func (f field) optional() bool {
+	return f.goType == "int" || f.goType == "float64" || f.goType == "bool"
+}
+
+// fieldType returns the Go type of the field in the params struct
+func (f field) fieldType() string {
+	if f.optional() {
+		return "*" + f.goType
+	}
+	return f.goType
+}
+
+type enumConst struct {
+	name  string
+	value string
+}
+
+func (g *generator) operation(plugin string, operation url.OperationSpec) {
+	ref := plugin + "." + operation.Method
+	funcName := g.unique(identifier(plugin) + identifier(operation.Method))
+	paramsName := g.unique(funcName + "Params")
+	defaultsName := g.unique(funcName + "Defaults")
+
+	fieldNames := map[string]bool{}
+	fields := make([]field, 0, len(operation.Params))
+	for _, param := range operation.Params {
+		f := field{ParamSpec: param}
+		f.name = param.Name
+		if f.name == "" {
+			f.name = param.Title
+		}
+		f.name = identifier(f.name)
+		if f.name == "" {
+			f.name = identifier(param.Identifier)
+		}
+		for base, i := f.name, 2; fieldNames[f.name]; i++ {
+			f.name = base + strconv.Itoa(i)
+		}
+		fieldNames[f.name] = true
+		f.goType, f.defValue = goType(param)
+		if param.Type == url.ParamEnum {
+			f.goType = g.unique(funcName + f.name)
+			constNames := map[string]bool{}
+			for _, value := range param.Enum {
+				name := f.goType + identifier(value)
+				for base, i := name, 2; constNames[name] || g.names[name]; i++ {
+					name = base + strconv.Itoa(i)
+				}
+				constNames[name] = true
+				g.names[name] = true
+				f.consts = append(f.consts, enumConst{name: name, value: value})
+			}
+		}
+		g.usesPtr = g.usesPtr || f.optional()
+		fields = append(fields, f)
+	}
+
+	for _, f := range fields {
+		if len(f.consts) == 0 {
+			continue
+		}
+		g.printf("\n// %s is the %s param of %s\n", f.goType, f.Identifier, ref)
+		g.printf("type %s string\n\n", f.goType)
+		g.printf("const (\n")
+		for _, c := range f.consts {
+			g.printf("%s %s = %q\n", c.name, f.goType, c.value)
+		}
+		g.printf(")\n")
+	}
+
+	g.printf("\n// %s are the params of %s%s.\n", paramsName, ref, sentence(operation.DisplayName))
+	g.printf("type %s struct {\n", paramsName)
+	for _, f := range fields {
+		g.printf("// %s is %s%s\n", f.name, f.Identifier, f.describe())
+		g.printf("%s %s\n", f.name, f.fieldType())
+	}
+	g.printf("}\n")
+
+	g.printf("\n// %s returns the params of %s set to their defaults\n", defaultsName, ref)
+	g.printf("func %s() %s {\n", defaultsName, paramsName)
+	g.printf("return %s{\n", paramsName)
+	for _, f := range fields {
+		switch {
+		case f.defValue == "":
+		case f.optional():
+			g.printf("%s: %s[%s](%s),\n", f.name, ptrFunc, f.goType, f.defValue)
+		default:
+			g.printf("%s: %s,\n", f.name, f.defValue)
+		}
+	}
+	g.printf("}\n}\n")
+
+	g.printf("\n// %s returns the %s operation%s.\n", funcName, ref, sentence(operation.Description))
+	g.printf("// Params equal to their default, nil and empty strings are left out of the url.\n")
+	g.printf("func %s(params %s) (url.Operation, error) {\n", funcName, paramsName)
+	g.printf("operation := url.Operation{Plugin: %q, Name: %q}\n", plugin, operation.Method)
+	for _, f := range fields {
+		g.validate(ref, f)
+	}
+	for _, f := range fields {
+		value := "params." + f.name
+		switch {
+		case f.optional() && f.defValue != "":
+			g.printf("if %s != nil && *%s != %s {\n", value, value, f.defValue)
+			g.printf("operation.Params = append(operation.Params, url.P(%q, *%s))\n}\n", f.Identifier, value)
+		case f.optional():
+			g.printf("if %s != nil {\n", value)
+			g.printf("operation.Params = append(operation.Params, url.P(%q, *%s))\n}\n", f.Identifier, value)
+		case f.defValue != "":
+			g.printf("if %s != \"\" && %s != %s {\n", value, value, f.defValue)
+			g.printf("operation.Params = append(operation.Params, url.P(%q, string(%s)))\n}\n", f.Identifier, value)
+		default:
+			g.printf("if %s != \"\" {\n", value)
+			g.printf("operation.Params = append(operation.Params, url.P(%q, string(%s)))\n}\n", f.Identifier, value)
+		}
+	}
+	g.printf("return operation, nil\n}\n")
+}
+
+// validate emits the enum and range checks of a param. Nil numbers and empty strings are not
+// set, so the zero value of a params struct passes.
+func (g *generator) validate(ref string, f field) {
+	value := "params." + f.name
+	if f.Type == url.ParamEnum {
+		g.usesFmt = true
+		cases := []string{`""`}
+		for _, c := range f.consts {
+			cases = append(cases, c.name)
+		}
+		g.printf("switch %s {\ncase %s:\ndefault:\n", value, strings.Join(cases, ", "))
+		g.printf("return url.Operation{}, fmt.Errorf(\"%s: invalid value %%q for %s\", %s)\n}\n", ref, f.Identifier, value)
+		return
+	}
+	if f.goType != "int" && f.goType != "float64" {
+		return
+	}
+	bounds := []string{}
+	if f.Min != nil {
+		bounds = append(bounds, fmt.Sprintf("*%s < %s", value, number(*f.Min, f.goType)))
+	}
+	if f.Max != nil {
+		bounds = append(bounds, fmt.Sprintf("*%s > %s", value, number(*f.Max, f.goType)))
+	}
+	if len(bounds) == 0 {
+		return
+	}
+	g.usesFmt = true
+	g.printf("if %s != nil && (%s) {\n", value, strings.Join(bounds, " || "))
+	g.printf("return url.Operation{}, fmt.Errorf(\"%s: %s should be %s, got %%v\", *%s)\n}\n", ref, f.Identifier, f.rangeText(), value)
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format, args...)
+}
+
+// unique returns name, or name with a number when it is already used
+func (g *generator) unique(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// describe returns the title, range, enum values and default of a param for its doc comment
+func (f field) describe() string {
+	parts := []string{}
+	if f.Title != "" {
+		parts = append(parts, oneLine(f.Title))
+	}
+	if f.Min != nil || f.Max != nil {
+		parts = append(parts, f.rangeText())
+	}
+	if len(f.Enum) > 0 {
+		parts = append(parts, "one of "+strings.Join(f.Enum, ", "))
+	}
+	if f.defValue != "" {
+		parts = append(parts, "defaults to "+f.defValue)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(parts, ", ")
+}
+
+func (f field) rangeText() string {
+	switch {
+	case f.Min != nil && f.Max != nil:
+		return fmt.Sprintf("between %s and %s", number(*f.Min, f.goType), number(*f.Max, f.goType))
+	case f.Min != nil:
+		return "at least " + number(*f.Min, f.goType)
+	case f.Max != nil:
+		return "at most " + number(*f.Max, f.goType)
+	}
+	return ""
+}
+
+// goType returns the Go type of a param and its default as a Go literal, empty without a default
+func goType(param url.ParamSpec) (string, string) {
+	switch param.Type {
+	case url.ParamInteger:
+		if v, ok := param.Default.(float64); ok {
+			return "int", number(v, "int")
+		}
+		return "int", ""
+	case url.ParamFloat:
+		if v, ok := param.Default.(float64); ok {
+			return "float64", number(v, "float64")
+		}
+		return "float64", ""
+	case url.ParamBoolean:
+		if v, ok := param.Default.(bool); ok {
+			return "bool", strconv.FormatBool(v)
+		}
+		return "bool", ""
+	}
+	if v, ok := param.Default.(string); ok && v != "" {
+		return "string", strconv.Quote(v)
+	}
+	return "string", ""
+}
+
+func number(v float64, goType string) string {
+	if goType == "int" {
+		return strconv.FormatInt(int64(math.Round(v)), 10)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// identifier turns a name such as "Industry Type", "toFormat" or "top-left" into an exported Go identifier
+func identifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	id := b.String()
+	if id != "" && unicode.IsDigit(rune(id[0])) {
+		id = "V" + id
+	}
+	return id
+}
+
+// sentence returns text as a clause for a doc comment
+func sentence(text string) string {
+	text = oneLine(text)
+	if text == "" {
+		return ""
+	}
+	return ", " + strings.TrimSuffix(text, ".")
+}
+
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,247 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldError is a metadata key that is missing or does not match its field
+type FieldError struct {
+	// Field is the path of the key, such as `dimensions.width` or `colors[2]`
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError lists every field of a struct that could not be decoded from metadata
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return "invalid metadata: " + strings.Join(messages, "; ")
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// Decode decodes asset metadata into a struct of type T. Keys are matched with the json
+// tags of the fields. Fields are required unless they are pointers or tagged omitempty.
+// A *ValidationError lists every missing or ill-typed key.
+func Decode[T any](metadata map[string]interface{}) (T, error) {
+	var result T
+	t := reflect.TypeOf(result)
+	if t == nil || t.Kind() != reflect.Struct {
+		return result, fmt.Errorf("metadata can only be decoded into a struct, got %v", t)
+	}
+	errs := []FieldError{}
+	validateStruct(t, metadata, "", &errs)
+	if len(errs) > 0 {
+		return result, &ValidationError{Errors: errs}
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// Encode encodes a struct into metadata for FileUpload, UrlUpload and UpdateFile
+func Encode(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	metadata := map[string]interface{}{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("metadata has to encode to a JSON object: %v", err)
+	}
+	return metadata, nil
+}
+
+func validateStruct(t reflect.Type, values map[string]interface{}, prefix string, errs *[]FieldError) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, optional, skip := jsonKey(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && name == field.Name {
+			// embedded structs are flattened like encoding/json does
+			validateStruct(field.Type, values, prefix, errs)
+			continue
+		}
+		fieldPath := name
+		if prefix != "" {
+			fieldPath = prefix + "." + name
+		}
+		value, ok := values[name]
+		if !ok || value == nil {
+			if !optional && field.Type.Kind() != reflect.Ptr {
+				*errs = append(*errs, FieldError{Field: fieldPath, Message: "is required"})
+			}
+			continue
+		}
+		validateValue(field.Type, value, fieldPath, errs)
+	}
+}
+
+func validateValue(t reflect.Type, value interface{}, fieldPath string, errs *[]FieldError) {
+	if value == nil {
+		if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface && t.Kind() != reflect.Slice && t.Kind() != reflect.Map {
+			*errs = append(*errs, FieldError{Field: fieldPath, Message: "cannot be null"})
+		}
+		return
+	}
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(data, reflect.New(t).Interface())
+		}
+		if err != nil {
+			*errs = append(*errs, FieldError{Field: fieldPath, Message: err.Error()})
+		}
+		return
+	}
+
+	v := reflect.ValueOf(value)
+	mismatch := func(expected string) {
+		*errs = append(*errs, FieldError{Field: fieldPath, Message: fmt.Sprintf("expected %s, got %s", expected, describe(value))})
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		validateValue(t.Elem(), value, fieldPath, errs)
+	case reflect.Interface:
+	case reflect.String:
+		if v.Kind() != reflect.String {
+			mismatch("string")
+		}
+	case reflect.Bool:
+		if v.Kind() != reflect.Bool {
+			mismatch("bool")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := toFloat(v)
+		if !ok || n != math.Trunc(n) {
+			mismatch("integer")
+		} else if overflows(t, n) {
+			mismatch(fmt.Sprintf("%v in range", t))
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := toFloat(v); !ok {
+			mismatch("number")
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			mismatch("array")
+			return
+		}
+		if t.Kind() == reflect.Array && v.Len() != t.Len() {
+			mismatch(fmt.Sprintf("array of %d items", t.Len()))
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			validateValue(t.Elem(), v.Index(i).Interface(), fmt.Sprintf("%s[%d]", fieldPath, i), errs)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok || t.Key().Kind() != reflect.String {
+			mismatch("object")
+			return
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			validateValue(t.Elem(), object[key], fieldPath+"."+key, errs)
+		}
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			mismatch("object")
+			return
+		}
+		validateStruct(t, object, fieldPath, errs)
+	default:
+		mismatch(t.String())
+	}
+}
+
+// jsonKey returns the metadata key of a field and whether it is optional or ignored
+func jsonKey(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	optional := false
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			optional = true
+		}
+	}
+	return name, optional, false
+}
+
+func toFloat(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	}
+	if number, ok := v.Interface().(json.Number); ok {
+		n, err := number.Float64()
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func overflows(t reflect.Type, n float64) bool {
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return n < 0 || reflect.New(t).Elem().OverflowUint(uint64(n))
+	}
+	return reflect.New(t).Elem().OverflowInt(int64(n))
+}
+
+func describe(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float32, float64, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return reflect.TypeOf(value).String()
+}
@@ -0,0 +1,136 @@
+package path
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Separator separates the folders of a Pixelbin path
+const Separator = "/"
+
+// Normalize trims surrounding slashes and drops empty segments, the root folder is ""
+func Normalize(p string) string {
+	return Join(p)
+}
+
+// Join joins path segments, dropping empty segments and surrounding slashes
+func Join(parts ...string) string {
+	segments := []string{}
+	for _, part := range parts {
+		for _, segment := range strings.Split(part, Separator) {
+			if segment != "" {
+				segments = append(segments, segment)
+			}
+		}
+	}
+	return strings.Join(segments, Separator)
+}
+
+// Split returns the parent path and the last segment of a path
+func Split(p string) (string, string) {
+	p = Normalize(p)
+	idx := strings.LastIndex(p, Separator)
+	if idx < 0 {
+		return "", p
+	}
+	return p[:idx], p[idx+1:]
+}
+
+// Segments returns the folders of a path, nil for the root folder
+func Segments(p string) []string {
+	p = Normalize(p)
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, Separator)
+}
+
+// IsWithin reports whether p is base or lies below it
+func IsWithin(p, base string) bool {
+	p, base = Normalize(p), Normalize(base)
+	return base == "" || p == base || strings.HasPrefix(p, base+Separator)
+}
+
+// Rebase moves p from below the from folder to below the to folder
+func Rebase(p, from, to string) string {
+	p, from = Normalize(p), Normalize(from)
+	if from != "" {
+		if p == from {
+			return Normalize(to)
+		}
+		p = strings.TrimPrefix(p, from+Separator)
+	}
+	return Join(to, p)
+}
+
+// ValidateName returns an error if name cannot be used as a file or folder name
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("name cannot be empty")
+	case name == "." || name == "..":
+		return fmt.Errorf("name cannot be %q", name)
+	case strings.Contains(name, Separator):
+		return fmt.Errorf("name %q cannot contain %q", name, Separator)
+	case strings.TrimSpace(name) != name:
+		return fmt.Errorf("name %q cannot start or end with whitespace", name)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("name %q cannot contain control characters", name)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if any segment of p is not a valid name. Surrounding slashes are allowed.
+func Validate(p string) error {
+	trimmed := strings.Trim(p, Separator)
+	if trimmed == "" {
+		return nil
+	}
+	for _, segment := range strings.Split(trimmed, Separator) {
+		if err := ValidateName(segment); err != nil {
+			return fmt.Errorf("invalid path %q: %v", p, err)
+		}
+	}
+	return nil
+}
+
+// Slug lowercases a name and replaces every run of characters other than letters and digits with a dash
+func Slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// FileId builds the fileId of a file, which is path/name.format
+func FileId(folderPath, name, format string) string {
+	fileId := Join(folderPath, name)
+	if format != "" {
+		fileId += "." + format
+	}
+	return fileId
+}
+
+// ParseFileId splits a fileId into the path, name and format of the file
+func ParseFileId(fileId string) (folderPath, name, format string) {
+	folderPath, name = Split(fileId)
+	if idx := strings.LastIndex(name, "."); idx > 0 {
+		name, format = name[:idx], name[idx+1:]
+	}
+	return folderPath, name, format
+}
@@ -0,0 +1,131 @@
+package url
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// Param types of the module catalogue. Types not listed here are passed as strings.
+const (
+	ParamInteger = "integer"
+	ParamFloat   = "float"
+	ParamBoolean = "boolean"
+	ParamEnum    = "enum"
+	ParamColor   = "color"
+	ParamString  = "string"
+)
+
+// Catalogue is the module catalogue returned by GetModules, with typed plugins and operations
+type Catalogue struct {
+	Plugins map[string]PluginSpec `json:"plugins"`
+}
+
+// PluginSpec describes a plugin of the catalogue, such as t or erase
+type PluginSpec struct {
+	Identifier  string                 `json:"identifier"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Credentials map[string]interface{} `json:"credentials"`
+	Operations  []OperationSpec        `json:"operations"`
+	Enabled     bool                   `json:"enabled"`
+}
+
+// OperationSpec describes an operation of a plugin, its Method is the name used in urls
+type OperationSpec struct {
+	Method      string     `json:"method"`
+	DisplayName string     `json:"displayName"`
+	Description string     `json:"description"`
+	Params      ParamSpecs `json:"params"`
+}
+
+// ParamSpec describes a parameter of an operation, its Identifier is the key used in urls
+type ParamSpec struct {
+	Identifier string      `json:"identifier"`
+	Name       string      `json:"name"`
+	Title      string      `json:"title"`
+	Type       string      `json:"type"`
+	Default    interface{} `json:"default"`
+	Enum       []string    `json:"enum"`
+	Min        *float64    `json:"min"`
+	Max        *float64    `json:"max"`
+}
+
+// ParamSpecs are the parameters of an operation. The catalogue lists them as an
+// array, or as a single object for operations with one parameter.
+type ParamSpecs []ParamSpec
+
+// UnmarshalJSON accepts an array of params or a single param object
+func (p *ParamSpecs) UnmarshalJSON(data []byte) error {
+	var list []ParamSpec
+	if err := json.Unmarshal(data, &list); err == nil {
+		*p = list
+		return nil
+	}
+	var single *ParamSpec
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*p = nil
+	if single != nil {
+		*p = ParamSpecs{*single}
+	}
+	return nil
+}
+
+// ParseCatalogue reads a catalogue from the JSON of GetModules, such as a saved snapshot
+func ParseCatalogue(data []byte) (*Catalogue, error) {
+	catalogue := &Catalogue{}
+	if err := json.Unmarshal(data, catalogue); err != nil {
+		return nil, err
+	}
+	if catalogue.Plugins == nil {
+		return nil, errors.New("catalogue has no plugins")
+	}
+	for id, plugin := range catalogue.Plugins {
+		if plugin.Identifier == "" {
+			plugin.Identifier = id
+			catalogue.Plugins[id] = plugin
+		}
+	}
+	return catalogue, nil
+}
+
+// CatalogueFromModules reads a catalogue from the response of GetModules
+func CatalogueFromModules(modules map[string]interface{}) (*Catalogue, error) {
+	data, err := json.Marshal(modules)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCatalogue(data)
+}
+
+// PluginIds returns the identifiers of the plugins in sorted order
+func (c *Catalogue) PluginIds() []string {
+	ids := make([]string, 0, len(c.Plugins))
+	for id := range c.Plugins {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Operation returns the spec of an operation, and whether the catalogue has it
+func (c *Catalogue) Operation(plugin, method string) (OperationSpec, bool) {
+	for _, operation := range c.Plugins[plugin].Operations {
+		if operation.Method == method {
+			return operation, true
+		}
+	}
+	return OperationSpec{}, false
+}
+
+// Param returns the spec of a parameter, and whether the operation has it
+func (o OperationSpec) Param(identifier string) (ParamSpec, bool) {
+	for _, param := range o.Params {
+		if param.Identifier == identifier {
+			return param, true
+		}
+	}
+	return ParamSpec{}, false
+}
@@ -0,0 +1,188 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrPresetNotFound is wrapped by the errors of a PresetResolver for presets which do not exist
+var ErrPresetNotFound = errors.New("preset not found")
+
+// PresetDefinition is the transformation of a preset and the params it declares, such as
+// t.resize(w:{w}) with {"w": {"type": "integer", "default": 200}}
+type PresetDefinition struct {
+	Transformation string
+	Params         map[string]interface{}
+}
+
+// PresetResolver returns the definition of a preset by name, a nil definition is treated as ErrPresetNotFound
+type PresetResolver func(name string) (*PresetDefinition, error)
+
+// PresetError reports a preset which could not be expanded
+type PresetError struct {
+	// Chain lists the presets being expanded, from the url down to the failing preset
+	Chain []string
+	Err   error
+}
+
+func (e *PresetError) Error() string {
+	return fmt.Sprintf("preset %s: %s", strings.Join(e.Chain, " -> "), e.Err.Error())
+}
+
+func (e *PresetError) Unwrap() error {
+	return e.Err
+}
+
+// WithPresetExpansion makes UrlToObj replace the presets of a url with the operations
+// they apply, the pattern of the url is left as it is
+func WithPresetExpansion(resolver PresetResolver) UrlToObjOption {
+	return func(config *urlToObjConfig) {
+		config.PresetResolver = resolver
+	}
+}
+
+// ExpandPresets returns the transformation with every preset replaced by the operations it
+// applies. The params of a preset reference are substituted into the placeholders of the preset,
+// written {name} or $name, and the defaults of the preset are used for the missing ones.
+// Presets referencing presets are expanded as well.
+func ExpandPresets(t *Transformation, resolver PresetResolver) (*Transformation, error) {
+	if resolver == nil {
+		return nil, errors.New("preset resolver is required")
+	}
+	return expandPresets(t, resolver, nil)
+}
+
+func expandPresets(t *Transformation, resolver PresetResolver, chain []string) (*Transformation, error) {
+	expanded := NewTransformation()
+	for _, operation := range t.Operations {
+		if operation.Plugin != PresetPlugin {
+			expanded.Append(operation)
+			continue
+		}
+		current := append(append([]string{}, chain...), operation.Name)
+		for _, name := range chain {
+			if name == operation.Name {
+				return nil, &PresetError{Chain: current, Err: errors.New("presets reference each other in a cycle")}
+			}
+		}
+		definition, err := resolver(operation.Name)
+		if err == nil && definition == nil {
+			err = ErrPresetNotFound
+		}
+		if err != nil {
+			return nil, &PresetError{Chain: current, Err: err}
+		}
+		values, err := presetValues(definition.Params, operation.Params)
+		if err != nil {
+			return nil, &PresetError{Chain: current, Err: err}
+		}
+		inner, err := ParsePattern(definition.Transformation)
+		if err != nil {
+			return nil, &PresetError{Chain: current, Err: err}
+		}
+		for i, innerOperation := range inner.Operations {
+			params := make([]Param, len(innerOperation.Params))
+			for j, param := range innerOperation.Params {
+				params[j] = param
+				if value, ok := values[placeholder(param.Value)]; ok {
+					params[j].Value = value
+				}
+			}
+			inner.Operations[i].Params = params
+		}
+		inner, err = expandPresets(inner, resolver, current)
+		if err != nil {
+			return nil, err
+		}
+		expanded.Append(inner.Operations...)
+	}
+	return expanded, nil
+}
+
+// presetValues returns the value of every declared param of a preset, from the reference or the defaults
+func presetValues(declared map[string]interface{}, given []Param) (map[string]string, error) {
+	values := map[string]string{}
+	for key, spec := range declared {
+		if spec, ok := spec.(map[string]interface{}); ok && spec["default"] != nil {
+			values[key] = P(key, spec["default"]).Value
+		}
+	}
+	for _, param := range given {
+		if _, ok := declared[param.Key]; !ok {
+			return nil, fmt.Errorf("unknown param %s", param.Key)
+		}
+		values[param.Key] = param.Value
+	}
+	missing := []string{}
+	for key := range declared {
+		if _, ok := values[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("no value for param %s", strings.Join(missing, ", "))
+	}
+	return values, nil
+}
+
+// placeholder returns the param name of a {name} or $name value, empty for other values
+func placeholder(value string) string {
+	if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+		return value[1 : len(value)-1]
+	}
+	if strings.HasPrefix(value, "$") {
+		return value[1:]
+	}
+	return ""
+}
+
+// PresetCache remembers the definitions returned by a resolver. It is safe for concurrent use.
+type PresetCache struct {
+	mu       sync.Mutex
+	resolver PresetResolver
+	presets  map[string]*PresetDefinition
+}
+
+// NewPresetCache returns a cache in front of resolver
+func NewPresetCache(resolver PresetResolver) *PresetCache {
+	return &PresetCache{resolver: resolver, presets: map[string]*PresetDefinition{}}
+}
+
+// Resolve returns the cached definition of a preset, resolving it on first use. Errors are not
+// cached, and a nil definition is returned as ErrPresetNotFound.
+func (c *PresetCache) Resolve(name string) (*PresetDefinition, error) {
+	c.mu.Lock()
+	definition, ok := c.presets[name]
+	c.mu.Unlock()
+	if ok {
+		return definition, nil
+	}
+	definition, err := c.resolver(name)
+	if err != nil {
+		return nil, err
+	}
+	if definition == nil {
+		return nil, ErrPresetNotFound
+	}
+	c.mu.Lock()
+	c.presets[name] = definition
+	c.mu.Unlock()
+	return definition, nil
+}
+
+// Invalidate drops the definitions of the given presets, or of every preset when none is given
+func (c *PresetCache) Invalidate(names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(names) == 0 {
+		c.presets = map[string]*PresetDefinition{}
+		return
+	}
+	for _, name := range names {
+		delete(c.presets, name)
+	}
+}
@@ -0,0 +1,246 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// templateSentinel marks the placeholders of a template while its url is built
+const templateSentinel = "\x00"
+
+// reservedTemplateChars cannot appear in rendered values, they would change the structure of the url
+const reservedTemplateChars = "~,()/?#&"
+
+// Template is a Pixelbin url with placeholders in the values of its transformation, such as
+// t.resize(w:{width},h:{height}). It is parsed and validated once, rendering only checks the
+// values and joins them with the literal parts of the url. It is safe for concurrent use.
+type Template struct {
+	source string
+	// segments are the literal parts of the url around the placeholders, one more than slots
+	segments []string
+	// slots are the placeholder names in the order they appear in the url
+	slots  []string
+	params map[string]ParamSpec
+	// fields caches the struct fields of each placeholder by struct type
+	fields sync.Map
+}
+
+// NewTemplate parses a url template. Every placeholder is declared in params by name, its Type,
+// Min, Max and Enum are checked on every render and its Default is used when no value is given.
+// Params without a Default must be given a value. The specs of the catalogue can be used as they
+// are, e.g. params["width"], _ = resize.Param("w").
+func NewTemplate(templateUrl string, params map[string]ParamSpec, opts ...UrlToObjOption) (template *Template, err error) {
+	defer func() {
+		if recover() != nil {
+			template, err = nil, fmt.Errorf("invalid url template %q", templateUrl)
+		}
+	}()
+	obj, err := UrlToObj(templateUrl, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if worker, _ := obj["worker"].(bool); worker {
+		return nil, errors.New("urls of the url translation worker cannot be templates")
+	}
+	t, err := TransformationFromMaps(obj["transformations"])
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[string]bool{}
+	for i, operation := range t.Operations {
+		for j, param := range operation.Params {
+			name := templatePlaceholder(param.Value)
+			if name == "" {
+				continue
+			}
+			spec, ok := params[name]
+			if !ok {
+				return nil, fmt.Errorf("placeholder {%s} is not declared", name)
+			}
+			if spec.Default != nil {
+				if err := checkTemplateValue(name, spec, P(name, spec.Default).Value); err != nil {
+					return nil, fmt.Errorf("default of %s", err.Error())
+				}
+			}
+			used[name] = true
+			t.Operations[i].Params[j].Value = templateSentinel + name + templateSentinel
+		}
+	}
+	unused := []string{}
+	for name := range params {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return nil, fmt.Errorf("params %s are not used by the template", strings.Join(unused, ", "))
+	}
+
+	obj["transformations"] = t.Maps()
+	if obj["cloudName"] == nil {
+		obj["isCustomDomain"] = true
+	}
+	rendered, err := ObjToUrl(obj)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(rendered, templateSentinel)
+	template = &Template{source: templateUrl, params: map[string]ParamSpec{}}
+	for i, part := range parts {
+		if i%2 == 0 {
+			template.segments = append(template.segments, part)
+		} else {
+			template.slots = append(template.slots, part)
+		}
+	}
+	for name, spec := range params {
+		template.params[name] = spec
+	}
+	return template, nil
+}
+
+// templatePlaceholder returns the name of a {name} value, empty for other values
+func templatePlaceholder(value string) string {
+	if len(value) > 2 && strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+		return value[1 : len(value)-1]
+	}
+	return ""
+}
+
+// String returns the url the template was made from
+func (t *Template) String() string {
+	return t.source
+}
+
+// Placeholders returns the names of the placeholders, sorted
+func (t *Template) Placeholders() []string {
+	names := make([]string, 0, len(t.params))
+	for name := range t.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render returns the url with the placeholders replaced by values. Values are formatted like P
+// formats them, empty strings are treated as missing and names which are not placeholders are an error.
+func (t *Template) Render(values map[string]interface{}) (string, error) {
+	for name := range values {
+		if _, ok := t.params[name]; !ok {
+			return "", fmt.Errorf("unknown placeholder %s", name)
+		}
+	}
+	return t.render(func(name string) (interface{}, bool) {
+		value, ok := values[name]
+		return value, ok
+	})
+}
+
+// RenderStruct returns the url with the placeholders replaced by the fields of a struct or a
+// pointer to one. A field is used for the placeholder named by its pixelbin tag, such as
+// `pixelbin:"width"`, else for the placeholder matching its name regardless of case. Nil
+// pointers and fields matching no placeholder are left out.
+func (t *Template) RenderStruct(value interface{}) (string, error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return "", fmt.Errorf("expected a struct, got %T", value)
+	}
+	fields := t.structFields(v.Type())
+	return t.render(func(name string) (interface{}, bool) {
+		index, ok := fields[name]
+		if !ok {
+			return nil, false
+		}
+		field := v.Field(index)
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				return nil, false
+			}
+			field = field.Elem()
+		}
+		return field.Interface(), true
+	})
+}
+
+// structFields returns the index of the field of each placeholder
+func (t *Template) structFields(structType reflect.Type) map[string]int {
+	if fields, ok := t.fields.Load(structType); ok {
+		return fields.(map[string]int)
+	}
+	fields := map[string]int{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if tag := field.Tag.Get("pixelbin"); tag != "" {
+			if _, ok := t.params[tag]; ok {
+				fields[tag] = i
+			}
+			continue
+		}
+		for name := range t.params {
+			if _, ok := fields[name]; !ok && strings.EqualFold(name, field.Name) {
+				fields[name] = i
+			}
+		}
+	}
+	t.fields.Store(structType, fields)
+	return fields
+}
+
+func (t *Template) render(lookup func(name string) (interface{}, bool)) (string, error) {
+	rendered := make([]string, len(t.slots))
+	size := 0
+	for i, name := range t.slots {
+		spec := t.params[name]
+		value, ok := lookup(name)
+		if !ok || value == nil || value == "" {
+			if spec.Default == nil {
+				return "", fmt.Errorf("no value for placeholder %s", name)
+			}
+			value = spec.Default
+		}
+		text := P(name, value).Value
+		if err := checkTemplateValue(name, spec, text); err != nil {
+			return "", err
+		}
+		rendered[i] = text
+		size += len(text)
+	}
+	var b strings.Builder
+	for _, segment := range t.segments {
+		size += len(segment)
+	}
+	b.Grow(size)
+	for i, segment := range t.segments {
+		b.WriteString(segment)
+		if i < len(rendered) {
+			b.WriteString(rendered[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// checkTemplateValue checks a rendered value against the spec of its placeholder
+func checkTemplateValue(name string, spec ParamSpec, value string) error {
+	if value == "" {
+		return fmt.Errorf("placeholder %s: empty value", name)
+	}
+	if strings.ContainsAny(value, reservedTemplateChars+templateSentinel) || strings.ContainsAny(value, " \t\r\n") {
+		return fmt.Errorf("placeholder %s: %q contains characters reserved in urls", name, value)
+	}
+	if code, message := checkParam(spec, value); code != "" {
+		return fmt.Errorf("placeholder %s: %s", name, message)
+	}
+	return nil
+}
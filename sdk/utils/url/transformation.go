@@ -0,0 +1,321 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PresetPlugin is the plugin of operations applying a preset, written as p:name(...)
+const PresetPlugin = "p"
+
+// Param is a parameter of an operation, such as w:200 in t.resize(w:200)
+type Param struct {
+	Key   string
+	Value string
+}
+
+// P builds a param, formatting numbers and booleans the way Pixelbin urls expect them
+func P(key string, value interface{}) Param {
+	switch v := value.(type) {
+	case string:
+		return Param{Key: key, Value: v}
+	case bool:
+		return Param{Key: key, Value: strconv.FormatBool(v)}
+	case float32:
+		return Param{Key: key, Value: strconv.FormatFloat(float64(v), 'f', -1, 32)}
+	case float64:
+		return Param{Key: key, Value: strconv.FormatFloat(v, 'f', -1, 64)}
+	}
+	return Param{Key: key, Value: fmt.Sprint(value)}
+}
+
+// Operation is a single step of a transformation, such as t.resize(w:200) or p:preset1
+type Operation struct {
+	Plugin string
+	Name   string
+	Params []Param
+}
+
+// String returns the operation as it appears in a url pattern
+func (o Operation) String() string {
+	params := make([]string, len(o.Params))
+	for i, param := range o.Params {
+		params[i] = param.Key + ":" + param.Value
+	}
+	joined := strings.Join(params, PARAMETER_SEPARATOR)
+	if o.Plugin == PresetPlugin {
+		if joined == "" {
+			return fmt.Sprintf("%s:%s", o.Plugin, o.Name)
+		}
+		return fmt.Sprintf("%s:%s(%s)", o.Plugin, o.Name, joined)
+	}
+	return fmt.Sprintf("%s.%s(%s)", o.Plugin, o.Name, joined)
+}
+
+// Transformation is a chain of operations applied in order. The methods returning a
+// *Transformation append an operation and return the transformation for chaining.
+type Transformation struct {
+	Operations []Operation
+}
+
+// NewTransformation returns an empty transformation, which serves the original file
+func NewTransformation() *Transformation {
+	return &Transformation{Operations: []Operation{}}
+}
+
+// Op appends an operation of any plugin. ParsePattern sorts params by key, pass them sorted
+// for the pattern to parse back into an equal transformation.
+func (t *Transformation) Op(plugin, name string, params ...Param) *Transformation {
+	t.Operations = append(t.Operations, Operation{Plugin: plugin, Name: name, Params: params})
+	return t
+}
+
+// Append appends operations built elsewhere, such as by generated constructors
+func (t *Transformation) Append(operations ...Operation) *Transformation {
+	t.Operations = append(t.Operations, operations...)
+	return t
+}
+
+// Preset appends a preset, params override the variables of the preset
+func (t *Transformation) Preset(name string, params ...Param) *Transformation {
+	return t.Op(PresetPlugin, name, params...)
+}
+
+// ResizeOptions are the parameters of t.resize, zero values are left out
+type ResizeOptions struct {
+	Width      int
+	Height     int
+	Fit        string
+	Background string
+	Position   string
+}
+
+// Resize appends t.resize, its params sorted by key like ParsePattern sorts them
+func (t *Transformation) Resize(options ResizeOptions) *Transformation {
+	params := []Param{}
+	if options.Background != "" {
+		params = append(params, P("b", options.Background))
+	}
+	if options.Fit != "" {
+		params = append(params, P("f", options.Fit))
+	}
+	if options.Height > 0 {
+		params = append(params, P("h", options.Height))
+	}
+	if options.Position != "" {
+		params = append(params, P("p", options.Position))
+	}
+	if options.Width > 0 {
+		params = append(params, P("w", options.Width))
+	}
+	return t.Op("t", "resize", params...)
+}
+
+// Compress appends t.compress with a quality between 1 and 100
+func (t *Transformation) Compress(quality int) *Transformation {
+	return t.Op("t", "compress", P("q", quality))
+}
+
+// Rotate appends t.rotate by angle degrees
+func (t *Transformation) Rotate(angle int) *Transformation {
+	return t.Op("t", "rotate", P("a", angle))
+}
+
+// Flip appends t.flip, mirroring vertically
+func (t *Transformation) Flip() *Transformation {
+	return t.Op("t", "flip")
+}
+
+// Flop appends t.flop, mirroring horizontally
+func (t *Transformation) Flop() *Transformation {
+	return t.Op("t", "flop")
+}
+
+// Blur appends t.blur with a sigma between 0.3 and 1000
+func (t *Transformation) Blur(sigma float64) *Transformation {
+	return t.Op("t", "blur", P("s", sigma))
+}
+
+// Grey appends t.grey, converting to greyscale
+func (t *Transformation) Grey() *Transformation {
+	return t.Op("t", "grey")
+}
+
+// ToFormat appends t.toFormat, such as webp or png
+func (t *Transformation) ToFormat(format string) *Transformation {
+	return t.Op("t", "toFormat", P("f", format))
+}
+
+// Pattern returns the url pattern of the transformation, original when it has no operations
+func (t *Transformation) Pattern() string {
+	if len(t.Operations) == 0 {
+		return "original"
+	}
+	operations := make([]string, len(t.Operations))
+	for i, operation := range t.Operations {
+		operations[i] = operation.String()
+	}
+	return strings.Join(operations, OPERTATION_SEPARATOR)
+}
+
+// URLOptions locate the file a transformation is applied to
+type URLOptions struct {
+	// CloudName of the organization, not used with a CustomDomain
+	CloudName string
+	// Zone is the 6 character zone slug, empty for the default zone
+	Zone string
+	// CustomDomain is the base url of a custom domain, such as https://assets.example.com
+	CustomDomain string
+	// BaseUrl defaults to https://cdn.pixelbin.io, not used with a CustomDomain
+	BaseUrl string
+	// DPR is the device pixel ratio between 0.1 and 5, zero leaves it out
+	DPR float64
+	// FAuto picks the best format for the browser
+	FAuto bool
+}
+
+// URL returns the url of filePath with the transformation applied
+func (t *Transformation) URL(filePath string, options URLOptions) (string, error) {
+	obj := map[string]interface{}{
+		"version":         "v2",
+		"filePath":        strings.TrimPrefix(filePath, "/"),
+		"transformations": t.Maps(),
+		"options":         map[string]interface{}{},
+	}
+	if options.CustomDomain != "" {
+		if options.CloudName != "" {
+			return "", errors.New("CloudName cannot be used with a CustomDomain")
+		}
+		obj["isCustomDomain"] = true
+		obj["baseUrl"] = strings.TrimSuffix(options.CustomDomain, "/")
+	} else {
+		if options.CloudName == "" {
+			return "", errors.New("CloudName should be defined")
+		}
+		obj["cloudName"] = options.CloudName
+		if options.BaseUrl != "" {
+			obj["baseUrl"] = strings.TrimSuffix(options.BaseUrl, "/")
+		}
+	}
+	if options.Zone != "" {
+		obj["zone"] = options.Zone
+	}
+	queryParams := obj["options"].(map[string]interface{})
+	if options.DPR != 0 {
+		queryParams["dpr"] = options.DPR
+	}
+	if options.FAuto {
+		queryParams["f_auto"] = true
+	}
+	return ObjToUrl(obj)
+}
+
+// Maps returns the transformation in the form of the transformations of UrlToObj
+func (t *Transformation) Maps() []map[string]interface{} {
+	maps := make([]map[string]interface{}, len(t.Operations))
+	for i, operation := range t.Operations {
+		maps[i] = map[string]interface{}{
+			"plugin": operation.Plugin,
+			"name":   operation.Name,
+		}
+		if len(operation.Params) > 0 {
+			values := make([]map[string]string, len(operation.Params))
+			for j, param := range operation.Params {
+				values[j] = map[string]string{"key": param.Key, "value": param.Value}
+			}
+			maps[i]["values"] = values
+		}
+	}
+	return maps
+}
+
+// TransformationFromMaps builds a transformation from the transformations of UrlToObj,
+// or of ObjToUrl. Values can be []map[string]string or []map[string]interface{}.
+// Entries without a name are skipped.
+func TransformationFromMaps(maps interface{}) (*Transformation, error) {
+	t := NewTransformation()
+	var entries []map[string]interface{}
+	switch list := maps.(type) {
+	case nil:
+		return t, nil
+	case []map[string]interface{}:
+		entries = list
+	case []interface{}:
+		for _, item := range list {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid transformation %v", item)
+			}
+			entries = append(entries, entry)
+		}
+	default:
+		return nil, fmt.Errorf("invalid transformations %T", maps)
+	}
+
+	for _, entry := range entries {
+		name, ok := entry["name"]
+		if !ok {
+			continue
+		}
+		params, err := paramsFromValues(entry["values"])
+		if err != nil {
+			return nil, err
+		}
+		t.Op(fmt.Sprint(entry["plugin"]), fmt.Sprint(name), params...)
+	}
+	return t, nil
+}
+
+func paramsFromValues(values interface{}) ([]Param, error) {
+	var items []map[string]interface{}
+	switch list := values.(type) {
+	case nil:
+	case []map[string]interface{}:
+		items = list
+	case []map[string]string:
+		for _, item := range list {
+			entry := map[string]interface{}{}
+			for key, value := range item {
+				entry[key] = value
+			}
+			items = append(items, entry)
+		}
+	case []interface{}:
+		for _, item := range list {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid transformation value %v", item)
+			}
+			items = append(items, entry)
+		}
+	default:
+		return nil, fmt.Errorf("invalid transformation values %T", values)
+	}
+
+	var params []Param
+	for _, item := range items {
+		key, ok := item["key"]
+		if !ok {
+			return nil, errors.New("key not specified")
+		}
+		value, ok := item["value"]
+		if !ok {
+			return nil, errors.New("value not specified for" + fmt.Sprint(key))
+		}
+		params = append(params, P(fmt.Sprint(key), value))
+	}
+	return params, nil
+}
+
+// ParsePattern builds a transformation from a url pattern such as t.resize(w:200)~p:preset1.
+// Params are sorted by key like in UrlToObj.
+func ParsePattern(pattern string) (t *Transformation, err error) {
+	defer func() {
+		if recover() != nil {
+			t, err = nil, fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}()
+	return TransformationFromMaps(getTransformationDetailsFromPattern(pattern, ""))
+}
@@ -52,6 +52,7 @@ func WithCustomDomain(IsCustomDomain bool) UrlToObjOption {
 
 type urlToObjConfig struct {
 	IsCustomDomain bool
+	PresetResolver PresetResolver
 }
 
 func UrlToObj(url string, opts ...UrlToObjOption) (map[string]interface{}, error) {
@@ -330,43 +331,29 @@ func getObjFromUrl(url string, config urlToObjConfig) (map[string]interface{}, e
 		url,
 	)
 	parts["transformations"] = transformations
+	if config.PresetResolver != nil {
+		t, err := TransformationFromMaps(transformations)
+		if err != nil {
+			return nil, err
+		}
+		expanded, err := ExpandPresets(t, config.PresetResolver)
+		if err != nil {
+			return nil, err
+		}
+		parts["transformations"] = expanded.Maps()
+	}
 	return parts, nil
 }
 
 func getPatternFromTransformations(tflist interface{}) (string, error) {
-	transformationList := tflist.([]map[string]interface{})
-	if len(transformationList) == 0 {
-		return "", nil
+	transformation, err := TransformationFromMaps(tflist)
+	if err != nil {
+		return "", err
 	}
-	newtransformationList := []string{}
-	for _, o := range transformationList {
-		if _, ok := o["name"]; ok {
-			if _, ok := o["values"]; !ok {
-				o["values"] = []map[string]interface{}{}
-			}
-			paramlist := []string{}
-			for _, items := range o["values"].([]map[string]interface{}) {
-				if _, ok := items["key"]; !ok {
-					return "", errors.New("key not specified")
-				}
-				if _, ok := items["value"]; !ok {
-					return "", errors.New("value not specified for" + items["key"].(string))
-				}
-				paramlist = append(paramlist, fmt.Sprintf("%s:%s", items["key"], items["value"]))
-			}
-			paramstr := strings.Join(paramlist, PARAMETER_SEPARATOR)
-			if o["plugin"] == "p" {
-				if len(paramstr) > 0 {
-					newtransformationList = append(newtransformationList, fmt.Sprintf("%s:%s(%s)", o["plugin"], o["name"], paramstr))
-				} else {
-					newtransformationList = append(newtransformationList, fmt.Sprintf("%s:%s", o["plugin"], o["name"]))
-				}
-			} else {
-				newtransformationList = append(newtransformationList, fmt.Sprintf("%s.%s(%s)", o["plugin"], o["name"], paramstr))
-			}
-		}
+	if len(transformation.Operations) == 0 {
+		return "", nil
 	}
-	return strings.Join(newtransformationList, OPERTATION_SEPARATOR), nil
+	return transformation.Pattern(), nil
 }
 
 func getUrlFromObj(obj map[string]interface{}) (string, error) {
@@ -437,7 +424,10 @@ func getUrlFromObj(obj map[string]interface{}) (string, error) {
 	}
 	queryArr := []string{}
 	if _, ok := obj["options"]; ok {
-		queryParams := obj["options"].(map[string]interface{})
+		queryParams, err := queryOptions(obj["options"])
+		if err != nil {
+			return "", err
+		}
 		if len(queryParams) > 0 {
 			if dpr, ok := queryParams["dpr"]; ok && dpr != "" {
 				dpr, err := parseDPR(dpr)
@@ -502,6 +492,37 @@ func validateFAuto(f_auto bool) (map[string]interface{}, error) {
 	return nil, nil
 }
 
+// queryOptions accepts the options of ObjToUrl, or the string options returned by UrlToObj
+func queryOptions(options interface{}) (map[string]interface{}, error) {
+	switch opts := options.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return opts, nil
+	case map[string]string:
+		queryParams := map[string]interface{}{}
+		if dpr, ok := opts["dpr"]; ok {
+			queryParams["dpr"] = dpr
+			if dpr != "auto" {
+				value, err := strconv.ParseFloat(dpr, 64)
+				if err != nil {
+					return nil, errors.New("Invalid DPR value")
+				}
+				queryParams["dpr"] = value
+			}
+		}
+		if fAuto, ok := opts["f_auto"]; ok {
+			value, err := strconv.ParseBool(fAuto)
+			if err != nil {
+				return nil, errors.New("F_auto value should be boolean")
+			}
+			queryParams["f_auto"] = value
+		}
+		return queryParams, nil
+	}
+	return nil, errors.New("invalid options")
+}
+
 func processQueryParams(urlParts map[string]interface{}) (map[string]string, error) {
 	queryParams := urlParts["search"].(map[string]string)
 	queryObj := map[string]string{}
@@ -0,0 +1,173 @@
+package url
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic codes reported by the validation of a transformation
+const (
+	DiagnosticUnknownPlugin    = "unknown-plugin"
+	DiagnosticDisabledPlugin   = "disabled-plugin"
+	DiagnosticUnknownOperation = "unknown-operation"
+	DiagnosticUnknownParam     = "unknown-param"
+	DiagnosticInvalidType      = "invalid-type"
+	DiagnosticOutOfRange       = "out-of-range"
+	DiagnosticInvalidEnum      = "invalid-enum"
+)
+
+var colorPattern = regexp.MustCompile(`^(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// Diagnostic is a problem found in an operation of a transformation
+type Diagnostic struct {
+	// Index of the operation in the transformation, starting at 0
+	Index int
+	// Operation as it appears in the pattern, such as t.resize(w:abc)
+	Operation string
+	// Param is the key of the param at fault, empty for problems of the operation itself
+	Param string
+	// Code is one of the Diagnostic constants
+	Code string
+	// Message describes the problem
+	Message string
+}
+
+// String returns the diagnostic as a single line
+func (d Diagnostic) String() string {
+	if d.Param == "" {
+		return fmt.Sprintf("operation %d %s: %s", d.Index, d.Operation, d.Message)
+	}
+	return fmt.Sprintf("operation %d %s: %s: %s", d.Index, d.Operation, d.Param, d.Message)
+}
+
+// Validate checks every operation of a transformation against the catalogue. It reports
+// unknown plugins, operations and params, disabled plugins, values of the wrong type, values
+// out of range and values missing from an enum. Presets are not checked.
+func (c *Catalogue) Validate(t *Transformation) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for i, operation := range t.Operations {
+		diagnostics = append(diagnostics, c.validateOperation(i, operation)...)
+	}
+	return diagnostics
+}
+
+// ValidatePattern parses a pattern such as t.resize(w:200)~t.flip() and validates it,
+// the error is only set when the pattern cannot be parsed
+func (c *Catalogue) ValidatePattern(pattern string) ([]Diagnostic, error) {
+	t, err := ParsePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return c.Validate(t), nil
+}
+
+// ValidateUrl parses a Pixelbin url and validates its pattern,
+// the error is only set when the url cannot be parsed
+func (c *Catalogue) ValidateUrl(pixelbinUrl string, opts ...UrlToObjOption) (diagnostics []Diagnostic, err error) {
+	defer func() {
+		if recover() != nil {
+			diagnostics, err = nil, fmt.Errorf("invalid pixelbin url %q", pixelbinUrl)
+		}
+	}()
+	obj, err := UrlToObj(pixelbinUrl, opts...)
+	if err != nil {
+		return nil, err
+	}
+	t, err := TransformationFromMaps(obj["transformations"])
+	if err != nil {
+		return nil, err
+	}
+	return c.Validate(t), nil
+}
+
+func (c *Catalogue) validateOperation(index int, operation Operation) []Diagnostic {
+	diagnostic := func(param, code, message string) Diagnostic {
+		return Diagnostic{Index: index, Operation: operation.String(), Param: param, Code: code, Message: message}
+	}
+	if operation.Plugin == PresetPlugin {
+		return nil
+	}
+	plugin, ok := c.Plugins[operation.Plugin]
+	if !ok {
+		return []Diagnostic{diagnostic("", DiagnosticUnknownPlugin, fmt.Sprintf("unknown plugin %q", operation.Plugin))}
+	}
+	diagnostics := []Diagnostic{}
+	if !plugin.Enabled {
+		diagnostics = append(diagnostics, diagnostic("", DiagnosticDisabledPlugin, fmt.Sprintf("plugin %q is not enabled", operation.Plugin)))
+	}
+	spec, ok := c.Operation(operation.Plugin, operation.Name)
+	if !ok {
+		return append(diagnostics, diagnostic("", DiagnosticUnknownOperation, fmt.Sprintf("unknown operation %q of plugin %q", operation.Name, operation.Plugin)))
+	}
+	for _, param := range operation.Params {
+		paramSpec, ok := spec.Param(param.Key)
+		if !ok {
+			diagnostics = append(diagnostics, diagnostic(param.Key, DiagnosticUnknownParam, "unknown param"))
+			continue
+		}
+		if code, message := checkParam(paramSpec, param.Value); code != "" {
+			diagnostics = append(diagnostics, diagnostic(param.Key, code, message))
+		}
+	}
+	return diagnostics
+}
+
+// checkParam returns the diagnostic code and message of an invalid value, or an empty code
+func checkParam(spec ParamSpec, value string) (string, string) {
+	switch spec.Type {
+	case ParamInteger, ParamFloat:
+		var number float64
+		var err error
+		if spec.Type == ParamInteger {
+			var integer int64
+			integer, err = strconv.ParseInt(value, 10, 64)
+			number = float64(integer)
+		} else {
+			number, err = strconv.ParseFloat(value, 64)
+		}
+		if err != nil {
+			return DiagnosticInvalidType, fmt.Sprintf("expected %s %s, got %q", article(spec.Type), spec.Type, value)
+		}
+		if (spec.Min != nil && number < *spec.Min) || (spec.Max != nil && number > *spec.Max) {
+			return DiagnosticOutOfRange, fmt.Sprintf("%s is out of range%s", value, rangeText(spec))
+		}
+	case ParamBoolean:
+		if value != "true" && value != "false" {
+			return DiagnosticInvalidType, fmt.Sprintf("expected a boolean, got %q", value)
+		}
+	case ParamEnum:
+		for _, allowed := range spec.Enum {
+			if value == allowed {
+				return "", ""
+			}
+		}
+		return DiagnosticInvalidEnum, fmt.Sprintf("%q is not one of %s", value, strings.Join(spec.Enum, ", "))
+	case ParamColor:
+		if !colorPattern.MatchString(value) {
+			return DiagnosticInvalidType, fmt.Sprintf("expected a hex color, got %q", value)
+		}
+	}
+	return "", ""
+}
+
+func article(word string) string {
+	if strings.ContainsRune("aeiou", rune(word[0])) {
+		return "an"
+	}
+	return "a"
+}
+
+func rangeText(spec ParamSpec) string {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	switch {
+	case spec.Min != nil && spec.Max != nil:
+		return fmt.Sprintf(" %s to %s", format(*spec.Min), format(*spec.Max))
+	case spec.Min != nil:
+		return ", minimum " + format(*spec.Min)
+	case spec.Max != nil:
+		return ", maximum " + format(*spec.Max)
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func TestArchiveAndUnarchiveFolder(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("drafts", "a", "png", []byte("a"), nil, map[string]interface{}{"owner": "design"})
+	fake.addFile("drafts/nested", "b", "png", []byte("b"), nil, nil)
+	fake.addFile("live", "c", "png", []byte("c"), nil, nil)
+	client := fake.client()
+
+	resp, err := client.Assets.Archive(platform.ArchiveXQuery{Source: "drafts"})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Files != 2 || resp.Folders != 2 || len(resp.Failures) != 0 {
+		t.Fatalf("Failed ! unexpected response %+v", resp)
+	}
+	file := fake.file("drafts/a.png")
+	if file.IsActive || file.Metadata["pixelbin:archivedAt"] == nil || file.Metadata["archivedAt"] != nil || file.Metadata["owner"] != "design" {
+		t.Errorf("Failed ! unexpected archived file %+v", file)
+	}
+	if !fake.inactive["drafts"] || !fake.inactive["drafts/nested"] {
+		t.Errorf("Failed ! folders were not archived")
+	}
+
+	archived, err := client.Assets.ListArchived(platform.ListArchivedXQuery{})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if len(archived) != 2 || archived[0].ArchivedAt == "" {
+		t.Errorf("Failed ! unexpected archived files %+v", archived)
+	}
+
+	resp, err = client.Assets.Unarchive(platform.ArchiveXQuery{Source: "drafts"})
+	if err != nil || resp.Files != 2 {
+		t.Fatalf("Failed ! got %+v, err %v", resp, err)
+	}
+	file = fake.file("drafts/a.png")
+	if !file.IsActive || file.Metadata[platform.ArchivedAtMetadataKey] != nil {
+		t.Errorf("Failed ! unexpected restored file %+v", file)
+	}
+	if fake.inactive["drafts"] {
+		t.Errorf("Failed ! folder was not restored")
+	}
+}
+
+func TestPurgeArchived(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	old := fake.addFile("bin", "old", "png", []byte("old"), nil, map[string]interface{}{
+		platform.ArchivedAtMetadataKey: time.Now().Add(-40 * 24 * time.Hour).UTC().Format(time.RFC3339),
+	})
+	old.IsActive = false
+	recent := fake.addFile("bin", "recent", "png", []byte("recent"), nil, map[string]interface{}{
+		platform.ArchivedAtMetadataKey: time.Now().UTC().Format(time.RFC3339),
+	})
+	recent.IsActive = false
+	fake.addFile("bin", "active", "png", []byte("active"), nil, nil)
+	client := fake.client()
+
+	resp, err := client.Assets.PurgeArchived(platform.PurgeArchivedXQuery{OlderThanDays: 30, DryRun: true})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if len(resp.Purged) != 1 || resp.Purged[0].FileId != "bin/old.png" || fake.file("bin/old.png") == nil {
+		t.Fatalf("Failed ! unexpected dry run %+v", resp)
+	}
+
+	resp, err = client.Assets.PurgeArchived(platform.PurgeArchivedXQuery{OlderThanDays: 30})
+	if err != nil || len(resp.Purged) != 1 || len(resp.Failures) != 0 {
+		t.Fatalf("Failed ! got %+v, err %v", resp, err)
+	}
+	if fake.file("bin/old.png") != nil || fake.file("bin/recent.png") == nil || fake.file("bin/active.png") == nil {
+		t.Errorf("Failed ! unexpected remaining files %v", fake.fileIds())
+	}
+}
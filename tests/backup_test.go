@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func TestBackupAndRestoreDirectory(t *testing.T) {
+	source := newFakePixelbin()
+	defer source.Close()
+	source.addFile("team", "logo", "png", []byte("logo-bytes"), []string{"brand"}, map[string]interface{}{"owner": "design"})
+	source.addFile("team/nested", "banner", "jpeg", []byte("banner-bytes"), nil, nil)
+	source.addFolder("team/empty")
+
+	destination := t.TempDir()
+	resp, err := source.client().Assets.Backup(platform.BackupXQuery{Path: "team", Destination: destination})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Assets != 2 || resp.Folders != 2 || len(resp.Failures) != 0 {
+		t.Fatalf("Failed ! unexpected response %+v", resp)
+	}
+	data, err := os.ReadFile(filepath.Join(destination, "assets", "team", "logo.png"))
+	if err != nil || !bytes.Equal(data, []byte("logo-bytes")) {
+		t.Errorf("Failed ! unexpected content %q, err %v", data, err)
+	}
+
+	resp, err = source.client().Assets.Backup(platform.BackupXQuery{Path: "team", Destination: destination, Incremental: true})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Skipped != 2 {
+		t.Errorf("Failed ! expected 2 skipped assets, got %+v", resp)
+	}
+
+	target := newFakePixelbin()
+	defer target.Close()
+	resp, err = target.client().Assets.Restore(platform.RestoreXQuery{Source: destination, Path: "restored"})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Assets != 2 || len(resp.Failures) != 0 {
+		t.Fatalf("Failed ! unexpected response %+v", resp)
+	}
+	logo := target.file("restored/logo.png")
+	if logo == nil || string(logo.content) != "logo-bytes" || logo.Tags[0] != "brand" {
+		t.Fatalf("Failed ! unexpected restored file %+v", logo)
+	}
+	if !reflect.DeepEqual(logo.Metadata, map[string]interface{}{"owner": "design"}) {
+		t.Errorf("Failed ! restored metadata %v does not match the source", logo.Metadata)
+	}
+	if target.file("restored/nested/banner.jpeg") == nil {
+		t.Errorf("Failed ! nested file was not restored, got %v", target.fileIds())
+	}
+	if _, ok := target.folders["restored/empty"]; !ok {
+		t.Errorf("Failed ! empty folder was not restored")
+	}
+}
+
+func TestBackupAndRestoreTar(t *testing.T) {
+	source := newFakePixelbin()
+	defer source.Close()
+	source.addFile("", "root", "jpeg", []byte("root-bytes"), nil, nil)
+
+	archive := filepath.Join(t.TempDir(), "backup.tar")
+	resp, err := source.client().Assets.Backup(platform.BackupXQuery{Destination: archive, Format: platform.TAR})
+	if err != nil || resp.Assets != 1 {
+		t.Fatalf("Failed ! got %+v, err %v", resp, err)
+	}
+
+	target := newFakePixelbin()
+	defer target.Close()
+	resp, err = target.client().Assets.Restore(platform.RestoreXQuery{Source: archive})
+	if err != nil || resp.Assets != 1 {
+		t.Fatalf("Failed ! got %+v, err %v", resp, err)
+	}
+	if file := target.file("root.jpeg"); file == nil || string(file.content) != "root-bytes" {
+		t.Errorf("Failed ! unexpected restored file %+v", file)
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func newBulkEditFixture() *fakePixelbin {
+	fake := newFakePixelbin()
+	fake.addFile("catalog", "shoe", "jpeg", []byte("shoe"), []string{"sale", "summer"}, map[string]interface{}{"sku": "1", "draft": true})
+	fake.addFile("catalog/kids", "hat", "png", []byte("hat"), []string{"new"}, nil)
+	fake.addFile("other", "logo", "png", []byte("logo"), []string{"sale"}, nil)
+	return fake
+}
+
+func TestBulkEditDryRun(t *testing.T) {
+	fake := newBulkEditFixture()
+	defer fake.Close()
+
+	resp, err := fake.client().Assets.BulkEdit(platform.BulkEditXQuery{
+		Path:          "catalog",
+		AddTags:       []string{"winter"},
+		RemoveTags:    []string{"sale"},
+		UnsetMetadata: []string{"draft"},
+		DryRun:        true,
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if !resp.DryRun || resp.Changed != 2 || len(resp.Results) != 2 {
+		t.Fatalf("Failed ! unexpected response %+v", resp)
+	}
+	if fake.requestCount("PATCH") != 0 {
+		t.Errorf("Failed ! dry run updated files")
+	}
+	for _, result := range resp.Results {
+		if result.FileId == "catalog/shoe.jpeg" {
+			if !reflect.DeepEqual(result.After.Tags, []string{"summer", "winter"}) {
+				t.Errorf("Failed ! unexpected tags %v", result.After.Tags)
+			}
+			if !reflect.DeepEqual(result.After.Metadata, map[string]interface{}{"sku": "1"}) {
+				t.Errorf("Failed ! unexpected metadata %v", result.After.Metadata)
+			}
+		}
+	}
+}
+
+func TestBulkEditApply(t *testing.T) {
+	fake := newBulkEditFixture()
+	defer fake.Close()
+	fake.failUpdates["catalog/kids/hat.png"] = true
+
+	resp, err := fake.client().Assets.BulkEdit(platform.BulkEditXQuery{
+		Query:       &platform.ListFilesXQuery{Tags: []interface{}{"sale"}},
+		RemoveTags:  []string{"sale"},
+		SetMetadata: map[string]interface{}{"archived": "yes"},
+		Access:      platform.PRIVATE,
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Changed != 2 || resp.Failed != 0 {
+		t.Fatalf("Failed ! unexpected response %+v", resp)
+	}
+	shoe := fake.file("catalog/shoe.jpeg")
+	if !reflect.DeepEqual(shoe.Tags, []string{"summer"}) || shoe.Metadata["archived"] != "yes" || shoe.Access != "private" {
+		t.Errorf("Failed ! unexpected file %+v", shoe)
+	}
+	if logo := fake.file("other/logo.png"); len(logo.Tags) != 0 {
+		t.Errorf("Failed ! expected tags to be cleared, got %v", logo.Tags)
+	}
+
+	resp, err = fake.client().Assets.BulkEdit(platform.BulkEditXQuery{Path: "catalog", AddTags: []string{"summer"}})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Unchanged != 1 || resp.Failed != 1 {
+		t.Errorf("Failed ! unexpected response %+v", resp)
+	}
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+const pluginsRequest = "GET " + assetsApi + "/playground/plugins"
+
+func TestCatalogueCache(t *testing.T) {
+	modules, err := os.ReadFile("testdata/modules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.setModules(modules)
+	snapshot := filepath.Join(t.TempDir(), "modules.json")
+
+	pixelbin := fake.client()
+	if err := pixelbin.Assets.SetCatalogueCache(platform.CatalogueCacheOptions{SnapshotPath: snapshot}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		catalogue, err := pixelbin.Assets.Catalogue()
+		if err != nil {
+			t.Fatalf("Failed ! got err %v", err)
+		}
+		if _, ok := catalogue.Operation("t", "resize"); !ok {
+			t.Errorf("Failed ! expected t.resize in the catalogue")
+		}
+	}
+	if count := fake.requestCount(pluginsRequest); count != 1 {
+		t.Errorf("Failed ! expected the catalogue to be fetched once, got %d", count)
+	}
+	if _, err := os.Stat(snapshot); err != nil {
+		t.Fatalf("Failed ! expected a snapshot, got err %v", err)
+	}
+
+	// a fresh snapshot is used by a new client without fetching
+	other := fake.client()
+	other.Assets.SetCatalogueCache(platform.CatalogueCacheOptions{SnapshotPath: snapshot})
+	if _, err := other.Assets.Catalogue(); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if count := fake.requestCount(pluginsRequest); count != 1 {
+		t.Errorf("Failed ! expected the snapshot to be used, got %d fetches", count)
+	}
+
+	// an expired snapshot is fetched again, and used when the fetch fails
+	expired := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(snapshot, expired, expired)
+	fake.setModules(nil)
+	stale := fake.client()
+	stale.Assets.SetCatalogueCache(platform.CatalogueCacheOptions{SnapshotPath: snapshot})
+	catalogue, err := stale.Assets.Catalogue()
+	if err != nil || catalogue.Plugins["erase"].Name != "EraseBG" {
+		t.Errorf("Failed ! expected the stale snapshot, got %v, err %v", catalogue, err)
+	}
+	if count := fake.requestCount(pluginsRequest); count != 2 {
+		t.Errorf("Failed ! expected an expired snapshot to be fetched again, got %d fetches", count)
+	}
+}
+
+func TestCatalogueEmbedded(t *testing.T) {
+	modules, err := os.ReadFile("testdata/modules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakePixelbin()
+	defer fake.Close()
+
+	pixelbin := fake.client()
+	if _, err := pixelbin.Assets.Catalogue(); err == nil {
+		t.Errorf("Failed ! expected an error without a catalogue")
+	}
+	if err := pixelbin.Assets.SetCatalogueCache(platform.CatalogueCacheOptions{Embedded: []byte("{}")}); err == nil {
+		t.Errorf("Failed ! expected an error for an invalid embedded catalogue")
+	}
+	pixelbin.Assets.SetCatalogueCache(platform.CatalogueCacheOptions{Embedded: modules})
+	catalogue, err := pixelbin.Assets.Catalogue()
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if ids := strings.Join(catalogue.PluginIds(), ","); ids != "erase,t,wm" {
+		t.Errorf("Failed ! expected the embedded catalogue, got %s", ids)
+	}
+}
+
+func TestRefreshCatalogue(t *testing.T) {
+	modules, err := os.ReadFile("testdata/modules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.setModules(modules)
+
+	pixelbin := fake.client()
+	pixelbin.Assets.SetCatalogueCache(platform.CatalogueCacheOptions{Embedded: modules})
+	changes, err := pixelbin.Assets.RefreshCatalogue()
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if changes.Changed() {
+		t.Errorf("Failed ! expected no changes, got %+v", changes)
+	}
+
+	updated := strings.Replace(string(modules), `"method": "flip"`, `"method": "grey"`, 1)
+	updated = strings.Replace(updated, `"wm": {`, `"af": {`, 1)
+	updated = strings.Replace(updated, `"identifier": "wm"`, `"identifier": "af"`, 1)
+	fake.setModules([]byte(updated))
+	changes, err = pixelbin.Assets.RefreshCatalogue()
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	expected := &platform.CatalogueChanges{
+		AddedPlugins:      []string{"af"},
+		RemovedPlugins:    []string{"wm"},
+		AddedOperations:   []string{"af.remove", "t.grey"},
+		RemovedOperations: []string{"t.flip", "wm.remove"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Failed ! expected %+v, got %+v", expected, changes)
+	}
+
+	fake.setModules(nil)
+	if _, err := pixelbin.Assets.RefreshCatalogue(); err == nil {
+		t.Errorf("Failed ! expected an error when the catalogue cannot be fetched")
+	}
+}
+
+func TestRefreshCatalogueSnapshotError(t *testing.T) {
+	modules, err := os.ReadFile("testdata/modules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.setModules(modules)
+
+	pixelbin := fake.client()
+	snapshot := filepath.Join(t.TempDir(), "missing", "modules.json")
+	pixelbin.Assets.SetCatalogueCache(platform.CatalogueCacheOptions{SnapshotPath: snapshot})
+	changes, err := pixelbin.Assets.RefreshCatalogue()
+	if err != nil {
+		t.Fatalf("Failed ! a snapshot error failed the refresh: %v", err)
+	}
+	if changes.SnapshotError == "" || len(changes.AddedPlugins) != 3 {
+		t.Errorf("Failed ! expected the changes and the snapshot error, got %+v", changes)
+	}
+	if _, err := pixelbin.Assets.Catalogue(); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if count := fake.requestCount(pluginsRequest); count != 1 {
+		t.Errorf("Failed ! expected the fetched catalogue to be kept in memory, got %d fetches", count)
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func newCollisionFixture(t *testing.T) (*fakePixelbin, string) {
+	fake := newFakePixelbin()
+	fake.addFile("catalog", "banner", "png", []byte("v1"), []string{"old"}, nil)
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("v2"))
+	}))
+	t.Cleanup(source.Close)
+	return fake, source.URL + "/images/banner.png"
+}
+
+func TestUploadCollisionStrategies(t *testing.T) {
+	cases := []struct {
+		strategy platform.CollisionStrategyEnum
+		fileIds  []string
+		content  string
+		err      bool
+	}{
+		{strategy: platform.FAIL, fileIds: []string{"catalog/banner.png"}, content: "v1", err: true},
+		{strategy: platform.OVERWRITE, fileIds: []string{"catalog/banner.png"}, content: "v2"},
+		{strategy: platform.AUTO_SUFFIX, fileIds: []string{"catalog/banner.png", "catalog/banner_1.png"}, content: "v1"},
+		{strategy: platform.SKIP_IF_EXISTS, fileIds: []string{"catalog/banner.png"}, content: "v1"},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			fake, sourceUrl := newCollisionFixture(t)
+			defer fake.Close()
+
+			resp, err := fake.client().Assets.UrlUpload(platform.UrlUploadXQuery{URL: sourceUrl, Path: "catalog", Collision: tc.strategy})
+			if (err != nil) != tc.err {
+				t.Fatalf("Failed ! got err %v", err)
+			}
+			if fileIds := strings.Join(fake.fileIds(), ","); fileIds != strings.Join(tc.fileIds, ",") {
+				t.Errorf("Failed ! expected files %v, got %v", tc.fileIds, fileIds)
+			}
+			if content := string(fake.file("catalog/banner.png").content); content != tc.content {
+				t.Errorf("Failed ! expected content %q, got %q", tc.content, content)
+			}
+			if tc.strategy == platform.SKIP_IF_EXISTS && (resp["fileId"] != "catalog/banner.png" || fake.requestCount("POST") != 0) {
+				t.Errorf("Failed ! expected the existing file without an upload, got %v", resp)
+			}
+		})
+	}
+}
+
+func TestUploadVersionArchive(t *testing.T) {
+	fake, sourceUrl := newCollisionFixture(t)
+	defer fake.Close()
+	client := fake.client()
+
+	if _, err := client.Assets.UrlUpload(platform.UrlUploadXQuery{URL: sourceUrl, Path: "catalog", Collision: platform.VERSION_ARCHIVE}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	fileIds := fake.fileIds()
+	if len(fileIds) != 2 || !strings.HasPrefix(fileIds[0], "catalog/.versions/banner_") {
+		t.Fatalf("Failed ! unexpected files %v", fileIds)
+	}
+	if string(fake.file(fileIds[0]).content) != "v1" || string(fake.file("catalog/banner.png").content) != "v2" {
+		t.Errorf("Failed ! the previous version was not archived")
+	}
+
+	// FileUpload derives the name from the uploaded file
+	local := filepath.Join(t.TempDir(), "banner.png")
+	os.WriteFile(local, []byte("v3"), 0o644)
+	file, _ := os.Open(local)
+	defer file.Close()
+	if _, err := client.Assets.FileUpload(platform.FileUploadXQuery{File: file, Path: "catalog", Collision: platform.VERSION_ARCHIVE}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if len(fake.fileIds()) != 3 || string(fake.file("catalog/banner.png").content) != "v3" {
+		t.Errorf("Failed ! unexpected files %v", fake.fileIds())
+	}
+}
+
+func TestUploadVersionArchiveRestoresOnFailure(t *testing.T) {
+	fake, _ := newCollisionFixture(t)
+	defer fake.Close()
+	client := fake.client()
+
+	// the fake cannot fetch a missing original, so the upload fails after the archive
+	missing := fake.server.URL + "/v2/test-cloud/original/missing/banner.png"
+	if _, err := client.Assets.UrlUpload(platform.UrlUploadXQuery{URL: missing, Path: "catalog", Collision: platform.VERSION_ARCHIVE}); err == nil {
+		t.Fatalf("Failed ! expected the upload to fail")
+	}
+	if fileIds := strings.Join(fake.fileIds(), ","); fileIds != "catalog/banner.png" {
+		t.Fatalf("Failed ! the archived version was not moved back, got files %v", fileIds)
+	}
+	if string(fake.file("catalog/banner.png").content) != "v1" {
+		t.Errorf("Failed ! the previous version was not restored")
+	}
+
+	_, err := client.Assets.CreateSignedUrlV2(platform.CreateSignedUrlV2XQuery{Path: "catalog", Name: "banner", Format: "png", Collision: platform.VERSION_ARCHIVE})
+	if err == nil {
+		t.Errorf("Failed ! expected an error for a signed url with VERSION_ARCHIVE")
+	}
+	if fake.requestCount("PATCH") != 2 || len(fake.fileIds()) != 1 {
+		t.Errorf("Failed ! a signed url archived the existing file, got files %v", fake.fileIds())
+	}
+}
+
+func TestUploadCollisionWithFlags(t *testing.T) {
+	fake, sourceUrl := newCollisionFixture(t)
+	defer fake.Close()
+
+	_, err := fake.client().Assets.UrlUpload(platform.UrlUploadXQuery{URL: sourceUrl, Path: "catalog", Overwrite: true, Collision: platform.FAIL})
+	if err == nil {
+		t.Errorf("Failed ! expected an error when combining Collision and Overwrite")
+	}
+	_, err = fake.client().Assets.UrlUpload(platform.UrlUploadXQuery{URL: sourceUrl, Path: "catalog", Collision: "replace"})
+	if err == nil {
+		t.Errorf("Failed ! expected an error for an invalid strategy")
+	}
+}
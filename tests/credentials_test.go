@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+const credentialModules = `{
+  "plugins": {
+    "ocr": {
+      "identifier": "ocr",
+      "credentials": {
+        "required": true,
+        "params": [
+          {"name": "API key", "type": "string", "identifier": "apiKey", "required": true},
+          {"name": "Region", "type": "enum", "enum": ["eu", "us"], "identifier": "region", "required": true},
+          {"name": "Timeout", "type": "integer", "identifier": "timeout"},
+          {"name": "Debug", "type": "boolean", "identifier": "debug"}
+        ]
+      },
+      "operations": [],
+      "enabled": true
+    },
+    "sign": {
+      "identifier": "sign",
+      "credentials": {
+        "required": true,
+        "params": {"name": "Secret", "type": "string", "identifier": "secret", "required": true}
+      },
+      "operations": [],
+      "enabled": true
+    },
+    "t": {
+      "identifier": "t",
+      "credentials": {"required": false},
+      "operations": [],
+      "enabled": true
+    }
+  }
+}`
+
+func TestCredentialSchema(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.setModules([]byte(credentialModules))
+	pixelbin := fake.client()
+
+	schema, err := pixelbin.Assets.GetCredentialSchema(platform.GetCredentialSchemaXQuery{PluginId: "ocr"})
+	if err != nil {
+		t.Fatalf("Failed ! %v", err)
+	}
+	if !schema.Required || len(schema.Params) != 4 || schema.Params[1].Identifier != "region" || !schema.Params[1].Required {
+		t.Errorf("Failed ! unexpected schema %+v", schema)
+	}
+	single, err := pixelbin.Assets.GetCredentialSchema(platform.GetCredentialSchemaXQuery{PluginId: "sign"})
+	if err != nil || len(single.Params) != 1 || single.Params[0].Identifier != "secret" {
+		t.Errorf("Failed ! unexpected schema %+v, err %v", single, err)
+	}
+	if _, err := pixelbin.Assets.GetCredentialSchema(platform.GetCredentialSchemaXQuery{PluginId: "missing"}); err == nil {
+		t.Errorf("Failed ! expected an error for a missing plugin")
+	}
+
+	valid := []map[string]interface{}{
+		{"apiKey": "key", "region": "eu"},
+		{"apiKey": "key", "region": "us", "timeout": 30, "debug": true},
+		{"apiKey": "key", "region": "us", "timeout": 30.0},
+	}
+	for _, credentials := range valid {
+		if err := schema.Validate(credentials); err != nil {
+			t.Errorf("Failed ! unexpected error for %v: %v", credentials, err)
+		}
+	}
+	invalid := []struct {
+		credentials map[string]interface{}
+		expected    string
+	}{
+		{map[string]interface{}{}, "apiKey is required; region is required"},
+		{map[string]interface{}{"apiKey": "key", "region": "asia"}, "region must be one of eu, us"},
+		{map[string]interface{}{"apiKey": 1, "region": "eu"}, "apiKey must be a string"},
+		{map[string]interface{}{"apiKey": "key", "region": "eu", "timeout": 1.5}, "timeout must be an integer"},
+		{map[string]interface{}{"apiKey": "key", "region": "eu", "debug": "yes"}, "debug must be a boolean"},
+		{map[string]interface{}{"apiKey": "key", "region": "eu", "secretKey": "x"}, "secretKey is not a credential of ocr"},
+	}
+	for _, test := range invalid {
+		err := schema.Validate(test.credentials)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Failed ! expected %q, got %v", test.expected, err)
+		}
+	}
+
+	if err := pixelbin.Assets.ValidateCredentials(platform.ValidateCredentialsXQuery{PluginId: "t", Credentials: map[string]interface{}{"any": "thing"}}); err != nil {
+		t.Errorf("Failed ! unexpected error for a plugin without declared credentials: %v", err)
+	}
+	if err := pixelbin.Assets.ValidateCredentials(platform.ValidateCredentialsXQuery{PluginId: "sign"}); err == nil {
+		t.Errorf("Failed ! expected an error for missing credentials")
+	}
+}
+
+func TestRotateCredentials(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.setModules([]byte(credentialModules))
+	previous := map[string]interface{}{"apiKey": "old", "region": "eu"}
+	fake.credentials["ocr"] = previous
+	pixelbin := fake.client()
+
+	var seen interface{}
+	result, err := pixelbin.Assets.RotateCredentials(platform.RotateCredentialsXQuery{
+		PluginId:    "ocr",
+		Credentials: map[string]interface{}{"apiKey": "new", "region": "eu"},
+		Previous:    previous,
+		Check: func() error {
+			seen = fake.credentials["ocr"]["apiKey"]
+			return nil
+		},
+	})
+	if err != nil || !result.Rotated || result.RolledBack || seen != "new" || fake.credentials["ocr"]["apiKey"] != "new" {
+		t.Errorf("Failed ! unexpected rotation %+v, err %v, check saw %v", result, err, seen)
+	}
+
+	result, err = pixelbin.Assets.RotateCredentials(platform.RotateCredentialsXQuery{
+		PluginId:    "ocr",
+		Credentials: map[string]interface{}{"apiKey": "broken", "region": "us"},
+		Previous:    map[string]interface{}{"apiKey": "new", "region": "eu"},
+		Check:       func() error { return errors.New("transformation failed") },
+	})
+	if err != nil || result.Rotated || !result.RolledBack || result.CheckError != "transformation failed" {
+		t.Errorf("Failed ! unexpected rollback %+v, err %v", result, err)
+	}
+	if fake.credentials["ocr"]["apiKey"] != "new" || fake.credentials["ocr"]["region"] != "eu" {
+		t.Errorf("Failed ! credentials not restored %v", fake.credentials["ocr"])
+	}
+
+	updates := fake.requestCount("PATCH " + assetsApi + "/credentials/ocr")
+	_, err = pixelbin.Assets.RotateCredentials(platform.RotateCredentialsXQuery{
+		PluginId:    "ocr",
+		Credentials: map[string]interface{}{"apiKey": "new"},
+		Previous:    previous,
+		Check:       func() error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), "region is required") {
+		t.Errorf("Failed ! expected a validation error, got %v", err)
+	}
+	if fake.requestCount("PATCH "+assetsApi+"/credentials/ocr") != updates {
+		t.Errorf("Failed ! invalid credentials were sent")
+	}
+
+	_, err = pixelbin.Assets.RotateCredentials(platform.RotateCredentialsXQuery{
+		PluginId:    "sign",
+		Credentials: map[string]interface{}{"secret": "s"},
+		Previous:    map[string]interface{}{"secret": "p"},
+		Check:       func() error { return nil },
+	})
+	if err == nil {
+		t.Errorf("Failed ! expected an error updating credentials which do not exist")
+	}
+
+	// a panic in Check rolls back like an error
+	result, err = pixelbin.Assets.RotateCredentials(platform.RotateCredentialsXQuery{
+		PluginId:    "ocr",
+		Credentials: map[string]interface{}{"apiKey": "panics", "region": "us"},
+		Previous:    map[string]interface{}{"apiKey": "new", "region": "eu"},
+		Check:       func() error { panic("nil image") },
+	})
+	if err != nil || !result.RolledBack || !strings.Contains(result.CheckError, "nil image") {
+		t.Errorf("Failed ! unexpected rollback after a panic %+v, err %v", result, err)
+	}
+	if fake.credentials["ocr"]["apiKey"] != "new" {
+		t.Errorf("Failed ! credentials not restored after a panic %v", fake.credentials["ocr"])
+	}
+
+	// previous credentials which could not be restored are rejected before changing anything
+	updates = fake.requestCount("PATCH " + assetsApi + "/credentials/ocr")
+	_, err = pixelbin.Assets.RotateCredentials(platform.RotateCredentialsXQuery{
+		PluginId:    "ocr",
+		Credentials: map[string]interface{}{"apiKey": "newer", "region": "eu"},
+		Previous:    map[string]interface{}{"apiKey": "new"},
+		Check:       func() error { return nil },
+	})
+	if err == nil || !strings.Contains(err.Error(), "Previous") || !strings.Contains(err.Error(), "region is required") {
+		t.Errorf("Failed ! expected a validation error for Previous, got %v", err)
+	}
+	if fake.requestCount("PATCH "+assetsApi+"/credentials/ocr") != updates {
+		t.Errorf("Failed ! credentials were updated with invalid previous credentials")
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func newDeleteFixture() *fakePixelbin {
+	fake := newFakePixelbin()
+	fake.addFile("old", "a", "png", []byte("aaaa"), []string{"brand"}, nil)
+	fake.addFile("old", "b", "png", []byte("bb"), nil, nil)
+	fake.addFile("old/nested", "c", "jpeg", []byte("c"), nil, nil)
+	fake.addFile("keep", "d", "png", []byte("d"), nil, nil)
+	return fake
+}
+
+func TestDeleteTreeDryRun(t *testing.T) {
+	fake := newDeleteFixture()
+	defer fake.Close()
+
+	resp, err := fake.client().Assets.DeleteTree(platform.DeleteTreeXQuery{Path: "old", DryRun: true})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Manifest.Files != 3 || resp.Manifest.Folders != 1 || resp.Manifest.Bytes != 7 || len(resp.Manifest.Paths) != 4 {
+		t.Errorf("Failed ! unexpected manifest %+v", resp.Manifest)
+	}
+	if len(fake.fileIds()) != 4 {
+		t.Errorf("Failed ! dry run deleted files")
+	}
+}
+
+func TestDeleteTree(t *testing.T) {
+	fake := newDeleteFixture()
+	defer fake.Close()
+
+	manifestPath := filepath.Join(t.TempDir(), "restore.json")
+	resp, err := fake.client().Assets.DeleteTree(platform.DeleteTreeXQuery{Path: "old", BatchSize: 2, RestoreManifest: manifestPath})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Deleted != 3 || len(resp.Failures) != 0 {
+		t.Fatalf("Failed ! unexpected response %+v", resp)
+	}
+	if fake.requestCount("POST "+assetsApi+"/files/delete") != 2 {
+		t.Errorf("Failed ! expected 2 batches")
+	}
+	if ids := fake.fileIds(); len(ids) != 1 || ids[0] != "keep/d.png" {
+		t.Errorf("Failed ! unexpected remaining files %v", ids)
+	}
+	if _, ok := fake.folders["old"]; ok {
+		t.Errorf("Failed ! folder was not deleted")
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restore platform.RestoreManifest
+	if err := json.Unmarshal(data, &restore); err != nil {
+		t.Fatal(err)
+	}
+	if len(restore.Files) != 3 || len(restore.Folders) != 1 {
+		t.Errorf("Failed ! unexpected restore manifest %+v", restore)
+	}
+}
+
+func TestDeleteTreeReportsPartialFailures(t *testing.T) {
+	fake := newDeleteFixture()
+	defer fake.Close()
+	fake.keepOnDelete["old/b.png"] = true
+
+	resp, err := fake.client().Assets.DeleteTree(platform.DeleteTreeXQuery{Path: "old"})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Deleted != 2 || len(resp.Failures) != 1 || resp.Failures[0].Path != "old/b.png" {
+		t.Errorf("Failed ! unexpected response %+v", resp)
+	}
+	if fake.file("old/b.png") == nil {
+		t.Errorf("Failed ! folder was deleted along with the failed file")
+	}
+}
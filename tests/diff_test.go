@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func TestDiffAndApplyAcrossOrganizations(t *testing.T) {
+	staging := newFakePixelbin()
+	defer staging.Close()
+	staging.addFile("team", "a", "png", []byte("a"), []string{"brand"}, nil)
+	staging.addFile("team", "b", "png", []byte("bb"), nil, nil)
+	staging.addFile("team/new", "c", "png", []byte("c"), nil, map[string]interface{}{"owner": "design"})
+	staging.addPreset("p1", "t.flip()", nil)
+	staging.addPreset("p2", "t.resize(w:100)", nil)
+
+	production := newFakePixelbin()
+	defer production.Close()
+	production.addFile("team", "a", "png", []byte("a"), nil, nil)
+	production.addFile("team", "b", "png", []byte("bbbb"), nil, nil)
+	production.addFile("team", "old", "png", []byte("old"), nil, nil)
+	production.addFolder("team/legacy")
+	production.addPreset("p1", "t.flop()", nil)
+	production.addPreset("p3", "t.rotate(a:90)", nil)
+
+	source, target := staging.client(), production.client()
+	query := platform.DiffXQuery{Path: "team", Target: target.Assets, IncludePresets: true}
+	diff, err := source.Assets.Diff(query)
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if diff.Added != 3 || diff.Changed != 3 || diff.Removed != 3 {
+		t.Fatalf("Failed ! unexpected diff\n%s", diff)
+	}
+	for _, line := range []string{"~ file b.png (size)\n", "~ file a.png (tags)\n", "+ folder new\n", "- preset p3\n"} {
+		if !strings.Contains(diff.String(), line) {
+			t.Errorf("Failed ! expected %q in\n%s", line, diff)
+		}
+	}
+
+	resp, err := source.Assets.ApplyDiff(platform.ApplyDiffXQuery{Diff: diff, Target: target.Assets})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Applied != 6 || resp.Skipped != 3 || len(resp.Failures) != 0 {
+		t.Fatalf("Failed ! unexpected response %+v", resp)
+	}
+	if file := production.file("team/b.png"); string(file.content) != "bb" {
+		t.Errorf("Failed ! changed content was not copied %+v", file)
+	}
+	if file := production.file("team/new/c.png"); file == nil || file.Metadata["owner"] != "design" {
+		t.Errorf("Failed ! added file was not copied %+v", file)
+	}
+	if production.file("team/old.png") == nil {
+		t.Errorf("Failed ! removed file was deleted without Delete")
+	}
+
+	diff, err = source.Assets.Diff(query)
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if diff.Added != 0 || diff.Changed != 0 || diff.Removed != 3 {
+		t.Fatalf("Failed ! expected only removed entries\n%s", diff)
+	}
+	resp, err = source.Assets.ApplyDiff(platform.ApplyDiffXQuery{Diff: diff, Target: target.Assets, Delete: true})
+	if err != nil || resp.Applied != 3 || len(resp.Failures) != 0 {
+		t.Fatalf("Failed ! got %+v, err %v", resp, err)
+	}
+	diff, err = source.Assets.Diff(query)
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if len(diff.Entries) != 0 {
+		t.Errorf("Failed ! expected no differences after applying, got\n%s", diff)
+	}
+}
+
+func TestApplyDiffRestoresPresetWhenAddFails(t *testing.T) {
+	staging := newFakePixelbin()
+	defer staging.Close()
+	staging.addPreset("p1", "t.unknown()", nil)
+
+	production := newFakePixelbin()
+	defer production.Close()
+	production.addPreset("p1", "t.flop()", nil)
+	production.presets["p1"]["archived"] = true
+	production.rejectedTransformations["t.unknown()"] = true
+
+	source, target := staging.client(), production.client()
+	diff, err := source.Assets.Diff(platform.DiffXQuery{Target: target.Assets, IncludePresets: true})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	resp, err := source.Assets.ApplyDiff(platform.ApplyDiffXQuery{Diff: diff, Target: target.Assets})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if len(resp.Failures) != 1 || !strings.Contains(resp.Failures[0].Error, "the previous preset was restored") {
+		t.Errorf("Failed ! unexpected response %+v", resp)
+	}
+	if preset := production.presets["p1"]; preset == nil || preset["transformation"] != "t.flop()" || preset["archived"] != true {
+		t.Errorf("Failed ! previous preset was not restored, got %v", preset)
+	}
+}
+
+func TestApplyDiffSkipsNestedRemovedFoldersWithoutDelete(t *testing.T) {
+	staging := newFakePixelbin()
+	defer staging.Close()
+	staging.addFolder("team")
+	production := newFakePixelbin()
+	defer production.Close()
+	production.addFolder("team/legacy/nested")
+
+	source, target := staging.client(), production.client()
+	diff, err := source.Assets.Diff(platform.DiffXQuery{Path: "team", Target: target.Assets})
+	if err != nil || diff.Removed != 2 {
+		t.Fatalf("Failed ! unexpected diff %v, err %v", diff, err)
+	}
+	resp, err := source.Assets.ApplyDiff(platform.ApplyDiffXQuery{Diff: diff, Target: target.Assets})
+	if err != nil || resp.Applied != 0 || resp.Skipped != 2 {
+		t.Fatalf("Failed ! expected both folders to be skipped without Delete, got %+v, err %v", resp, err)
+	}
+	resp, err = source.Assets.ApplyDiff(platform.ApplyDiffXQuery{Diff: diff, Target: target.Assets, Delete: true})
+	if err != nil || resp.Applied != 2 || len(resp.Failures) != 0 {
+		t.Fatalf("Failed ! unexpected response %+v, err %v", resp, err)
+	}
+	if _, ok := production.folders["team/legacy"]; ok {
+		t.Errorf("Failed ! the removed folder was not deleted")
+	}
+}
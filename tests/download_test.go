@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+var downloadContent = []byte("pixelbin-download-test-content")
+var testModTime = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newDownloadServer() (*httptest.Server, *[]string) {
+	requested := []string{}
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	mux.HandleFunc("/service/platform/assets/v1.0/files/", func(w http.ResponseWriter, r *http.Request) {
+		fileId := strings.TrimPrefix(r.URL.Path, "/service/platform/assets/v1.0/files/")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"fileId": fileId,
+			"access": "public-read",
+			"url":    fmt.Sprintf("%s/v2/test-cloud/original/%s.jpeg", server.URL, fileId),
+		})
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		w.Header().Set("ETag", `"etag-1"`)
+		http.ServeContent(w, r, "asset.jpeg", testModTime, bytes.NewReader(downloadContent))
+	})
+	return server, &requested
+}
+
+func newTestClient(domain string) *platform.PixelbinClient {
+	testConfig := platform.NewPixelbinConfig("test-api-secret", domain)
+	testConfig.SetOAuthClient()
+	return platform.NewPixelbinClient(testConfig)
+}
+
+func TestDownloadByFileId(t *testing.T) {
+	server, requested := newDownloadServer()
+	defer server.Close()
+	client := newTestClient(server.URL)
+
+	var buf bytes.Buffer
+	sum := sha256.Sum256(downloadContent)
+	resp, err := client.Assets.Download(platform.DownloadXQuery{
+		FileId:   "dir/asset",
+		Writer:   &buf,
+		Checksum: hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), downloadContent) {
+		t.Errorf("Failed ! expected %q got %q", downloadContent, buf.Bytes())
+	}
+	if resp.BytesWritten != int64(len(downloadContent)) || resp.ETag != `"etag-1"` {
+		t.Errorf("Failed ! unexpected response %+v", resp)
+	}
+	if (*requested)[0] != "/v2/test-cloud/original/dir/asset.jpeg" {
+		t.Errorf("Failed ! unexpected url %s", (*requested)[0])
+	}
+}
+
+func TestDownloadWithTransformations(t *testing.T) {
+	server, requested := newDownloadServer()
+	defer server.Close()
+	client := newTestClient(server.URL)
+
+	var buf bytes.Buffer
+	_, err := client.Assets.Download(platform.DownloadXQuery{
+		URL: server.URL + "/v2/test-cloud/original/dir/asset.jpeg",
+		Transformations: []map[string]interface{}{
+			{
+				"plugin": "t",
+				"name":   "resize",
+				"values": []map[string]interface{}{{"key": "w", "value": "100"}},
+			},
+		},
+		Writer: &buf,
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if (*requested)[0] != "/v2/test-cloud/t.resize(w:100)/dir/asset.jpeg" {
+		t.Errorf("Failed ! unexpected url %s", (*requested)[0])
+	}
+
+	// query options of the url are kept
+	_, err = client.Assets.Download(platform.DownloadXQuery{
+		URL:             server.URL + "/v2/test-cloud/original/dir/asset.jpeg?dpr=2.5&f_auto=true",
+		Transformations: []map[string]interface{}{{"plugin": "t", "name": "flip"}},
+		Writer:          &buf,
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if (*requested)[1] != "/v2/test-cloud/t.flip()/dir/asset.jpeg?dpr=2.5&f_auto=true" {
+		t.Errorf("Failed ! unexpected url %s", (*requested)[1])
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	server, _ := newDownloadServer()
+	defer server.Close()
+	client := newTestClient(server.URL)
+
+	file, err := os.CreateTemp(t.TempDir(), "download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.Write(downloadContent[:10])
+
+	sum := sha256.Sum256(downloadContent)
+	resp, err := client.Assets.Download(platform.DownloadXQuery{
+		URL:      server.URL + "/v2/test-cloud/original/dir/asset.jpeg",
+		Writer:   file,
+		Offset:   10,
+		Checksum: hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.StatusCode != http.StatusPartialContent || resp.BytesWritten != int64(len(downloadContent)-10) {
+		t.Errorf("Failed ! unexpected response %+v", resp)
+	}
+	data, _ := os.ReadFile(file.Name())
+	if !bytes.Equal(data, downloadContent) {
+		t.Errorf("Failed ! expected %q got %q", downloadContent, data)
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	server, _ := newDownloadServer()
+	defer server.Close()
+	client := newTestClient(server.URL)
+
+	var buf bytes.Buffer
+	_, err := client.Assets.Download(platform.DownloadXQuery{
+		URL:               server.URL + "/v2/test-cloud/original/dir/asset.jpeg",
+		Writer:            &buf,
+		Checksum:          "0000",
+		ChecksumAlgorithm: platform.MD5,
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "checksum mismatch") {
+		t.Errorf("Failed ! expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestDownloadPrivateAssetRequiresToken(t *testing.T) {
+	client := newTestClient("https://api.testdomain.com")
+	_, err := client.Assets.Download(platform.DownloadXQuery{
+		File:   &platform.FilesResponse{URL: "https://cdn.pixelbin.io/v2/test-cloud/original/a.jpeg", Access: platform.PRIVATE},
+		Writer: &bytes.Buffer{},
+	})
+	if err == nil {
+		t.Errorf("Failed ! expected error for unsigned private asset")
+	}
+}
@@ -0,0 +1,594 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+const assetsApi = "/service/platform/assets/v1.0"
+
+// fakeFile is an asset held by fakePixelbin
+type fakeFile struct {
+	ID       string                 `json:"_id"`
+	Name     string                 `json:"name"`
+	Path     string                 `json:"path"`
+	FileId   string                 `json:"fileId"`
+	Format   string                 `json:"format"`
+	Size     float64                `json:"size"`
+	Access   string                 `json:"access"`
+	IsActive bool                   `json:"isActive"`
+	Tags     []string               `json:"tags"`
+	Metadata map[string]interface{} `json:"metadata"`
+	URL      string                 `json:"url"`
+	content  []byte
+}
+
+// fakePixelbin is an in-memory stand-in for the Pixelbin API and CDN
+type fakePixelbin struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	nextID   int
+	folders  map[string]string
+	inactive map[string]bool
+	files    map[string]*fakeFile
+	requests []string
+	// failUpdates makes PATCH requests on these fileIds fail
+	failUpdates map[string]bool
+	// keepOnDelete leaves these fileIds out of bulk deletes
+	keepOnDelete map[string]bool
+	// racedFolders are created by a concurrent client right before CreateFolder runs
+	racedFolders map[string]bool
+	presets      map[string]map[string]interface{}
+	// concurrentEdits are applied to a file one by one, right after it is read
+	concurrentEdits map[string][]func(file *fakeFile)
+	// modules is the catalogue served by GetModules, which fails while it is nil
+	modules []byte
+	// contexts are served by GetTransformationContext by url, other urls are not found
+	contexts map[string]map[string]interface{}
+	// credentials holds the credentials of each plugin
+	credentials map[string]map[string]interface{}
+	// rejectedTransformations make AddPreset fail for presets applying them
+	rejectedTransformations map[string]bool
+}
+
+func newFakePixelbin() *fakePixelbin {
+	f := &fakePixelbin{
+		folders:      map[string]string{},
+		inactive:     map[string]bool{},
+		files:        map[string]*fakeFile{},
+		failUpdates:  map[string]bool{},
+		keepOnDelete: map[string]bool{},
+		racedFolders: map[string]bool{},
+		presets:      map[string]map[string]interface{}{},
+		contexts:     map[string]map[string]interface{}{},
+		credentials:  map[string]map[string]interface{}{},
+
+		rejectedTransformations: map[string]bool{},
+		concurrentEdits:         map[string][]func(file *fakeFile){},
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *fakePixelbin) Close() {
+	f.server.Close()
+}
+
+func (f *fakePixelbin) client() *platform.PixelbinClient {
+	return newTestClient(f.server.URL)
+}
+
+func (f *fakePixelbin) id() string {
+	f.nextID++
+	return fmt.Sprintf("id-%d", f.nextID)
+}
+
+func (f *fakePixelbin) addFolder(folderPath string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.addFolderLocked(folderPath)
+}
+
+func (f *fakePixelbin) addFolderLocked(folderPath string) string {
+	parts := strings.Split(strings.Trim(folderPath, "/"), "/")
+	for i := range parts {
+		current := strings.Join(parts[:i+1], "/")
+		if _, ok := f.folders[current]; !ok {
+			f.folders[current] = f.id()
+		}
+	}
+	return f.folders[strings.Join(parts, "/")]
+}
+
+func (f *fakePixelbin) addFile(folderPath, name, format string, content []byte, tags []string, metadata map[string]interface{}) *fakeFile {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.addFileLocked(folderPath, name, format, content, "public-read", tags, metadata)
+}
+
+func (f *fakePixelbin) addFileLocked(folderPath, name, format string, content []byte, access string, tags []string, metadata map[string]interface{}) *fakeFile {
+	folderPath = strings.Trim(folderPath, "/")
+	if folderPath != "" {
+		f.addFolderLocked(folderPath)
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	file := &fakeFile{
+		ID:       f.id(),
+		Name:     name,
+		Path:     folderPath,
+		Format:   format,
+		Size:     float64(len(content)),
+		Access:   access,
+		IsActive: true,
+		Tags:     tags,
+		Metadata: metadata,
+		content:  content,
+	}
+	f.setFileId(file)
+	f.files[file.FileId] = file
+	return file
+}
+
+func (f *fakePixelbin) setFileId(file *fakeFile) {
+	file.FileId = strings.TrimPrefix(file.Path+"/"+file.Name+"."+file.Format, "/")
+	file.URL = f.server.URL + "/v2/test-cloud/original/" + file.FileId
+}
+
+func (f *fakePixelbin) file(fileId string) *fakeFile {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.files[fileId]
+}
+
+func (f *fakePixelbin) fileIds() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := []string{}
+	for id := range f.files {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (f *fakePixelbin) setModules(modules []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.modules = modules
+}
+
+func (f *fakePixelbin) requestCount(prefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for _, r := range f.requests {
+		if strings.HasPrefix(r, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"message": message, "status": status})
+}
+
+func (f *fakePixelbin) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	route := strings.TrimPrefix(r.URL.Path, assetsApi)
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v2/test-cloud/original/"):
+		fileId := strings.TrimPrefix(r.URL.Path, "/v2/test-cloud/original/")
+		file, ok := f.files[fileId]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", fmt.Sprintf(`"%s-%d"`, file.ID, len(file.content)))
+		w.Write(file.content)
+	case r.Method == "GET" && route == "/listFiles":
+		f.listFiles(w, r)
+	case r.Method == "POST" && route == "/files/delete":
+		var body struct {
+			Ids []string `json:"ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		deleted := []*fakeFile{}
+		for _, id := range body.Ids {
+			for fileId, file := range f.files {
+				if file.ID == id && !f.keepOnDelete[fileId] {
+					deleted = append(deleted, file)
+					delete(f.files, fileId)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(deleted)
+	case strings.HasPrefix(route, "/files/"):
+		f.fileRoute(w, r, strings.TrimPrefix(route, "/files/"))
+	case r.Method == "POST" && route == "/folders":
+		var body struct {
+			Name string `json:"name"`
+			Path string `json:"path"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		full := strings.Trim(body.Path+"/"+body.Name, "/")
+		if f.racedFolders[full] {
+			f.addFolderLocked(full)
+		}
+		if _, ok := f.folders[full]; ok {
+			writeError(w, http.StatusConflict, "Folder already exists")
+			return
+		}
+		id := f.addFolderLocked(full)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"_id": id, "name": body.Name, "path": strings.Trim(body.Path, "/"), "isActive": true})
+	case strings.HasPrefix(route, "/folders/"):
+		f.folderRoute(w, r, strings.TrimPrefix(route, "/folders/"))
+	case r.Method == "POST" && route == "/upload/direct":
+		f.directUpload(w, r)
+	case r.Method == "POST" && route == "/upload/url":
+		var body struct {
+			URL       string   `json:"url"`
+			Path      string   `json:"path"`
+			Name      string   `json:"name"`
+			Access    string   `json:"access"`
+			Tags      []string `json:"tags"`
+			Metadata  map[string]interface{}
+			Overwrite bool `json:"overwrite"`
+
+			FilenameOverride bool `json:"filenameOverride"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		content, format, ok := f.fetch(body.URL)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Unable to fetch url")
+			return
+		}
+		if body.Name == "" {
+			body.Name = strings.TrimSuffix(path.Base(body.URL), path.Ext(body.URL))
+		}
+		f.upload(w, body.Path, body.Name, format, body.Access, body.Tags, body.Metadata, body.Overwrite, body.FilenameOverride, content)
+	case r.Method == "GET" && route == "/playground/plugins":
+		if f.modules == nil {
+			writeError(w, http.StatusServiceUnavailable, "Service unavailable")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(f.modules)
+	case r.Method == "GET" && r.URL.Path == "/service/platform/transformation/context":
+		context, ok := f.contexts[r.URL.Query().Get("url")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Url not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"context": context})
+	case r.Method == "GET" && strings.HasPrefix(route, "/playground/plugins/"):
+		var modules struct {
+			Plugins map[string]interface{} `json:"plugins"`
+		}
+		json.Unmarshal(f.modules, &modules)
+		plugin, ok := modules.Plugins[strings.TrimPrefix(route, "/playground/plugins/")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Plugin not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, plugin)
+	case route == "/credentials" || strings.HasPrefix(route, "/credentials/"):
+		f.credentialsRoute(w, r, strings.TrimPrefix(strings.TrimPrefix(route, "/credentials"), "/"))
+	case route == "/presets" || strings.HasPrefix(route, "/presets/"):
+		f.presetRoute(w, r, strings.TrimPrefix(strings.TrimPrefix(route, "/presets"), "/"))
+	default:
+		writeError(w, http.StatusNotFound, "Not found "+r.URL.Path)
+	}
+}
+
+func (f *fakePixelbin) listFiles(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	folderPath := strings.Trim(query.Get("path"), "/")
+	name := query.Get("name")
+	items := []map[string]interface{}{}
+	if query.Get("onlyFiles") != "true" {
+		for full, id := range f.folders {
+			parent, folderName := path.Split(full)
+			if strings.Trim(parent, "/") != folderPath || (name != "" && folderName != name) {
+				continue
+			}
+			items = append(items, map[string]interface{}{"_id": id, "name": folderName, "path": folderPath, "type": "folder"})
+		}
+	}
+	// ListFiles sends tags formatted with %v, a tag search matches files in nested folders too
+	tags := strings.Fields(strings.Trim(query.Get("tags"), "[]"))
+	if query.Get("onlyFolders") != "true" {
+		for _, file := range f.files {
+			if len(tags) > 0 {
+				if !hasTags(file.Tags, tags) || (folderPath != "" && file.Path != folderPath && !strings.HasPrefix(file.Path, folderPath+"/")) {
+					continue
+				}
+			} else if file.Path != folderPath || (name != "" && file.Name != name) {
+				continue
+			}
+			items = append(items, map[string]interface{}{
+				"_id": file.ID, "name": file.Name, "path": file.Path, "type": "file", "fileId": file.FileId,
+				"format": file.Format, "size": file.Size, "access": file.Access,
+			})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i]["type"].(string)+items[i]["name"].(string) > items[j]["type"].(string)+items[j]["name"].(string)
+	})
+	pageNo, _ := strconv.Atoi(query.Get("pageNo"))
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if pageNo < 1 {
+		pageNo = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	start, end := (pageNo-1)*pageSize, pageNo*pageSize
+	if start > len(items) {
+		start = len(items)
+	}
+	if end > len(items) {
+		end = len(items)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"items": items[start:end],
+		"page":  map[string]interface{}{"type": "number", "size": pageSize, "current": pageNo, "hasNext": end < len(items), "itemTotal": len(items)},
+	})
+}
+
+// fetch returns the content of a CDN url, either served by this fake or by another server
+func (f *fakePixelbin) fetch(rawUrl string) ([]byte, string, bool) {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, "", false
+	}
+	format := strings.TrimPrefix(path.Ext(parsed.Path), ".")
+	if strings.HasPrefix(rawUrl, f.server.URL+"/") {
+		source, ok := f.files[strings.TrimPrefix(parsed.Path, "/v2/test-cloud/original/")]
+		if !ok {
+			return nil, "", false
+		}
+		return source.content, source.Format, true
+	}
+	res, err := http.Get(rawUrl)
+	if err != nil || res.StatusCode != http.StatusOK {
+		return nil, "", false
+	}
+	defer res.Body.Close()
+	content, err := io.ReadAll(res.Body)
+	return content, format, err == nil
+}
+
+func (f *fakePixelbin) addPreset(name, transformation string, params map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.presets[name] = map[string]interface{}{"presetName": name, "transformation": transformation, "params": params, "archived": false}
+}
+
+func (f *fakePixelbin) credentialsRoute(w http.ResponseWriter, r *http.Request, pluginId string) {
+	var body struct {
+		PluginId    string                 `json:"pluginId"`
+		Credentials map[string]interface{} `json:"credentials"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+	if r.Method == "POST" {
+		pluginId = body.PluginId
+		if _, ok := f.credentials[pluginId]; ok {
+			writeError(w, http.StatusConflict, "Credentials already exist")
+			return
+		}
+	} else if _, ok := f.credentials[pluginId]; !ok {
+		writeError(w, http.StatusNotFound, "Credentials not found")
+		return
+	}
+	switch r.Method {
+	case "POST", "PATCH":
+		f.credentials[pluginId] = body.Credentials
+	case "DELETE":
+		delete(f.credentials, pluginId)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pluginId": pluginId, "credentials": body.Credentials})
+}
+
+func (f *fakePixelbin) presetRoute(w http.ResponseWriter, r *http.Request, name string) {
+	switch {
+	case name == "" && r.Method == "GET":
+		names := []string{}
+		for presetName := range f.presets {
+			names = append(names, presetName)
+		}
+		sort.Strings(names)
+		items := []map[string]interface{}{}
+		for _, presetName := range names {
+			items = append(items, f.presets[presetName])
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"items": items,
+			"page":  map[string]interface{}{"type": "number", "size": len(items), "current": 1, "hasNext": false},
+		})
+	case name == "" && r.Method == "POST":
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		presetName, _ := body["presetName"].(string)
+		if transformation, _ := body["transformation"].(string); f.rejectedTransformations[transformation] {
+			writeError(w, http.StatusBadRequest, "Invalid transformation")
+			return
+		}
+		if _, ok := f.presets[presetName]; ok {
+			writeError(w, http.StatusConflict, "Preset already exists")
+			return
+		}
+		body["archived"] = false
+		f.presets[presetName] = body
+		writeJSON(w, http.StatusOK, body)
+	default:
+		preset, ok := f.presets[name]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Preset not found")
+			return
+		}
+		switch r.Method {
+		case "DELETE":
+			delete(f.presets, name)
+		case "PATCH":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			preset["archived"] = body["archived"] == true
+		}
+		writeJSON(w, http.StatusOK, preset)
+	}
+}
+
+func hasTags(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		found := false
+		for _, t := range tags {
+			found = found || t == tag
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *fakePixelbin) fileRoute(w http.ResponseWriter, r *http.Request, fileId string) {
+	file, ok := f.files[fileId]
+	if !ok {
+		writeError(w, http.StatusNotFound, "File not found")
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, file)
+		if edits := f.concurrentEdits[fileId]; len(edits) > 0 {
+			edits[0](file)
+			f.concurrentEdits[fileId] = edits[1:]
+		}
+	case "DELETE":
+		delete(f.files, fileId)
+		writeJSON(w, http.StatusOK, file)
+	case "PATCH":
+		if f.failUpdates[fileId] {
+			writeError(w, http.StatusBadRequest, "Update failed")
+			return
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["metadata"]; ok {
+			// metadata is replaced as a whole rather than merged
+			file.Metadata = nil
+		}
+		data, _ := json.Marshal(body)
+		json.Unmarshal(data, file)
+		if _, ok := body["isActive"]; !ok {
+			file.IsActive = true
+		}
+		delete(f.files, fileId)
+		f.setFileId(file)
+		if file.Path != "" {
+			f.addFolderLocked(file.Path)
+		}
+		f.files[file.FileId] = file
+		writeJSON(w, http.StatusOK, file)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (f *fakePixelbin) folderRoute(w http.ResponseWriter, r *http.Request, id string) {
+	for full, folderId := range f.folders {
+		if folderId != id {
+			continue
+		}
+		parent, name := path.Split(full)
+		switch r.Method {
+		case "DELETE":
+			for other := range f.folders {
+				if other == full || strings.HasPrefix(other, full+"/") {
+					delete(f.folders, other)
+				}
+			}
+			for fileId, file := range f.files {
+				if file.Path == full || strings.HasPrefix(file.Path, full+"/") {
+					delete(f.files, fileId)
+				}
+			}
+		case "PATCH":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			active, _ := body["isActive"].(bool)
+			f.inactive[full] = !active
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"_id": id, "name": name, "path": strings.Trim(parent, "/"), "isActive": !f.inactive[full]})
+		return
+	}
+	writeError(w, http.StatusNotFound, "Folder not found")
+}
+
+func (f *fakePixelbin) directUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	upload, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	content, _ := io.ReadAll(upload)
+	ext := path.Ext(header.Filename)
+	name := r.FormValue("name")
+	if name == "" {
+		name = strings.TrimSuffix(path.Base(header.Filename), ext)
+	}
+	metadata := map[string]interface{}{}
+	json.Unmarshal([]byte(r.FormValue("metadata")), &metadata)
+	f.upload(w, r.FormValue("path"), name, strings.TrimPrefix(ext, "."), r.FormValue("access"), r.MultipartForm.Value["tags"], metadata, r.FormValue("overwrite") == "true", r.FormValue("filenameOverride") == "true", content)
+}
+
+func (f *fakePixelbin) upload(w http.ResponseWriter, folderPath, name, format, access string, tags []string, metadata map[string]interface{}, overwrite, filenameOverride bool, content []byte) {
+	if access == "" {
+		access = "public-read"
+	}
+	fileIdOf := func(name string) string {
+		return strings.TrimPrefix(strings.Trim(folderPath, "/")+"/"+name+"."+format, "/")
+	}
+	if _, ok := f.files[fileIdOf(name)]; ok && !overwrite {
+		if !filenameOverride {
+			writeError(w, http.StatusConflict, "File already exists")
+			return
+		}
+		base := name
+		for i := 1; f.files[fileIdOf(name)] != nil; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+	}
+	file := f.addFileLocked(folderPath, name, format, content, access, tags, metadata)
+	writeJSON(w, http.StatusOK, file)
+}
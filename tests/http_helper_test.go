@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+)
+
+// The request signature includes a host header. Sent as a header it would follow the Host line
+// net/http writes, and servers reject requests with two Host headers.
+func TestHttpRequestHostHeader(t *testing.T) {
+	var host, signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, signature = r.Host, r.Header.Get("x-ebg-signature")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	_, err := common.HttpRequest("GET", server.URL, nil, nil, map[string]string{"host": "api.pixelbin.io", "x-ebg-signature": "v1:abc"})
+	if err != nil {
+		t.Fatalf("Failed ! %v", err)
+	}
+	if host != "api.pixelbin.io" || signature != "v1:abc" {
+		t.Errorf("Failed ! got host %q and signature %q", host, signature)
+	}
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func findBucket(buckets []platform.InventoryBucket, key string) platform.InventoryBucket {
+	for _, bucket := range buckets {
+		if bucket.Key == key {
+			return bucket
+		}
+	}
+	return platform.InventoryBucket{}
+}
+
+func TestInventory(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("marketing", "a", "png", []byte("aaaa"), []string{"brand"}, nil)
+	fake.addFile("marketing/social", "b", "jpeg", []byte("bb"), []string{"brand", "social"}, nil)
+	fake.addFile("sales", "c", "png", []byte("c"), nil, nil)
+	fake.addFolder("sales/empty")
+
+	progress := []platform.InventoryProgress{}
+	report, err := fake.client().Assets.Inventory(platform.InventoryXQuery{
+		IncludeTags: true,
+		Progress:    func(p platform.InventoryProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if report.Files != 3 || report.Folders != 4 || report.Bytes != 7 {
+		t.Errorf("Failed ! unexpected totals %+v", report)
+	}
+	if bucket := findBucket(report.ByFolder, "marketing"); bucket.Files != 2 || bucket.Bytes != 6 {
+		t.Errorf("Failed ! unexpected marketing bucket %+v", bucket)
+	}
+	if bucket := findBucket(report.ByFolder, platform.InventoryRootKey); bucket.Files != 3 {
+		t.Errorf("Failed ! unexpected root bucket %+v", bucket)
+	}
+	if bucket := findBucket(report.ByFolder, "sales/empty"); bucket.Key == "" || bucket.Files != 0 {
+		t.Errorf("Failed ! expected an empty bucket for the empty folder, got %+v", bucket)
+	}
+	if bucket := findBucket(report.ByFormat, "png"); bucket.Files != 2 || bucket.Bytes != 5 {
+		t.Errorf("Failed ! unexpected png bucket %+v", bucket)
+	}
+	if bucket := findBucket(report.ByTag, "brand"); bucket.Files != 2 || bucket.Bytes != 6 {
+		t.Errorf("Failed ! unexpected brand bucket %+v", bucket)
+	}
+	if bucket := findBucket(report.ByAccess, "public-read"); bucket.Files != 3 {
+		t.Errorf("Failed ! unexpected access bucket %+v", bucket)
+	}
+	if len(progress) == 0 || progress[len(progress)-1].Files != 3 {
+		t.Errorf("Failed ! unexpected progress %+v", progress)
+	}
+
+	var csvOut bytes.Buffer
+	if err := report.WriteCSV(&csvOut); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(csvOut.String(), "dimension,key,files,bytes\ntotal,/,3,7\n") || !strings.Contains(csvOut.String(), "tag,social,1,2\n") {
+		t.Errorf("Failed ! unexpected csv %s", csvOut.String())
+	}
+	var jsonOut bytes.Buffer
+	if err := report.WriteJSON(&jsonOut); err != nil {
+		t.Fatal(err)
+	}
+	var decoded platform.InventoryReport
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil || decoded.Bytes != 7 {
+		t.Errorf("Failed ! unexpected json %s, err %v", jsonOut.String(), err)
+	}
+}
@@ -0,0 +1,3 @@
+package operations
+
+//go:generate go run ../../cmd/pixelbin-gen -snapshot ../testdata/modules.json -package operations -out operations.go
@@ -0,0 +1,275 @@
+// Code generated by pixelbin-gen. DO NOT EDIT.
+
+// Package operations has typed constructors for the operations of the Pixelbin module catalogue
+package operations
+
+import (
+	"fmt"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+// Ptr returns a pointer to v, to set the number and boolean params
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// EraseBgIndustryType is the i param of erase.bg
+type EraseBgIndustryType string
+
+const (
+	EraseBgIndustryTypeGeneral   EraseBgIndustryType = "general"
+	EraseBgIndustryTypeEcommerce EraseBgIndustryType = "ecommerce"
+)
+
+// EraseBgParams are the params of erase.bg, Remove background of an image.
+type EraseBgParams struct {
+	// IndustryType is i, Industry type, one of general, ecommerce, defaults to "general"
+	IndustryType EraseBgIndustryType
+}
+
+// EraseBgDefaults returns the params of erase.bg set to their defaults
+func EraseBgDefaults() EraseBgParams {
+	return EraseBgParams{
+		IndustryType: "general",
+	}
+}
+
+// EraseBg returns the erase.bg operation, Remove the background of any image.
+// Params equal to their default, nil and empty strings are left out of the url.
+func EraseBg(params EraseBgParams) (url.Operation, error) {
+	operation := url.Operation{Plugin: "erase", Name: "bg"}
+	switch params.IndustryType {
+	case "", EraseBgIndustryTypeGeneral, EraseBgIndustryTypeEcommerce:
+	default:
+		return url.Operation{}, fmt.Errorf("erase.bg: invalid value %q for i", params.IndustryType)
+	}
+	if params.IndustryType != "" && params.IndustryType != "general" {
+		operation.Params = append(operation.Params, url.P("i", string(params.IndustryType)))
+	}
+	return operation, nil
+}
+
+// TResizeFit is the f param of t.resize
+type TResizeFit string
+
+const (
+	TResizeFitCover   TResizeFit = "cover"
+	TResizeFitContain TResizeFit = "contain"
+	TResizeFitFill    TResizeFit = "fill"
+	TResizeFitInside  TResizeFit = "inside"
+	TResizeFitOutside TResizeFit = "outside"
+)
+
+// TResizePosition is the p param of t.resize
+type TResizePosition string
+
+const (
+	TResizePositionTop         TResizePosition = "top"
+	TResizePositionBottom      TResizePosition = "bottom"
+	TResizePositionLeft        TResizePosition = "left"
+	TResizePositionRight       TResizePosition = "right"
+	TResizePositionRightTop    TResizePosition = "right_top"
+	TResizePositionRightBottom TResizePosition = "right_bottom"
+	TResizePositionLeftTop     TResizePosition = "left_top"
+	TResizePositionLeftBottom  TResizePosition = "left_bottom"
+	TResizePositionCenter      TResizePosition = "center"
+)
+
+// TResizeParams are the params of t.resize, Resize.
+type TResizeParams struct {
+	// Height is h, Height, between 0 and 10000, defaults to 0
+	Height *int
+	// Width is w, Width, between 0 and 10000, defaults to 0
+	Width *int
+	// Fit is f, Fit, one of cover, contain, fill, inside, outside, defaults to "cover"
+	Fit TResizeFit
+	// Background is b, Background color, defaults to "000000"
+	Background string
+	// Position is p, Position, one of top, bottom, left, right, right_top, right_bottom, left_top, left_bottom, center, defaults to "center"
+	Position TResizePosition
+}
+
+// TResizeDefaults returns the params of t.resize set to their defaults
+func TResizeDefaults() TResizeParams {
+	return TResizeParams{
+		Height:     Ptr[int](0),
+		Width:      Ptr[int](0),
+		Fit:        "cover",
+		Background: "000000",
+		Position:   "center",
+	}
+}
+
+// TResize returns the t.resize operation, Resize an image.
+// Params equal to their default, nil and empty strings are left out of the url.
+func TResize(params TResizeParams) (url.Operation, error) {
+	operation := url.Operation{Plugin: "t", Name: "resize"}
+	if params.Height != nil && (*params.Height < 0 || *params.Height > 10000) {
+		return url.Operation{}, fmt.Errorf("t.resize: h should be between 0 and 10000, got %v", *params.Height)
+	}
+	if params.Width != nil && (*params.Width < 0 || *params.Width > 10000) {
+		return url.Operation{}, fmt.Errorf("t.resize: w should be between 0 and 10000, got %v", *params.Width)
+	}
+	switch params.Fit {
+	case "", TResizeFitCover, TResizeFitContain, TResizeFitFill, TResizeFitInside, TResizeFitOutside:
+	default:
+		return url.Operation{}, fmt.Errorf("t.resize: invalid value %q for f", params.Fit)
+	}
+	switch params.Position {
+	case "", TResizePositionTop, TResizePositionBottom, TResizePositionLeft, TResizePositionRight, TResizePositionRightTop, TResizePositionRightBottom, TResizePositionLeftTop, TResizePositionLeftBottom, TResizePositionCenter:
+	default:
+		return url.Operation{}, fmt.Errorf("t.resize: invalid value %q for p", params.Position)
+	}
+	if params.Height != nil && *params.Height != 0 {
+		operation.Params = append(operation.Params, url.P("h", *params.Height))
+	}
+	if params.Width != nil && *params.Width != 0 {
+		operation.Params = append(operation.Params, url.P("w", *params.Width))
+	}
+	if params.Fit != "" && params.Fit != "cover" {
+		operation.Params = append(operation.Params, url.P("f", string(params.Fit)))
+	}
+	if params.Background != "" && params.Background != "000000" {
+		operation.Params = append(operation.Params, url.P("b", string(params.Background)))
+	}
+	if params.Position != "" && params.Position != "center" {
+		operation.Params = append(operation.Params, url.P("p", string(params.Position)))
+	}
+	return operation, nil
+}
+
+// TCompressParams are the params of t.compress, Compress.
+type TCompressParams struct {
+	// Quality is q, Quality, between 1 and 100, defaults to 80
+	Quality *int
+}
+
+// TCompressDefaults returns the params of t.compress set to their defaults
+func TCompressDefaults() TCompressParams {
+	return TCompressParams{
+		Quality: Ptr[int](80),
+	}
+}
+
+// TCompress returns the t.compress operation, Compress an image.
+// Params equal to their default, nil and empty strings are left out of the url.
+func TCompress(params TCompressParams) (url.Operation, error) {
+	operation := url.Operation{Plugin: "t", Name: "compress"}
+	if params.Quality != nil && (*params.Quality < 1 || *params.Quality > 100) {
+		return url.Operation{}, fmt.Errorf("t.compress: q should be between 1 and 100, got %v", *params.Quality)
+	}
+	if params.Quality != nil && *params.Quality != 80 {
+		operation.Params = append(operation.Params, url.P("q", *params.Quality))
+	}
+	return operation, nil
+}
+
+// TBlurParams are the params of t.blur, Blur.
+type TBlurParams struct {
+	// Sigma is s, Sigma, between 0.3 and 1000, defaults to 0.3
+	Sigma *float64
+}
+
+// TBlurDefaults returns the params of t.blur set to their defaults
+func TBlurDefaults() TBlurParams {
+	return TBlurParams{
+		Sigma: Ptr[float64](0.3),
+	}
+}
+
+// TBlur returns the t.blur operation, Blur an image.
+// Params equal to their default, nil and empty strings are left out of the url.
+func TBlur(params TBlurParams) (url.Operation, error) {
+	operation := url.Operation{Plugin: "t", Name: "blur"}
+	if params.Sigma != nil && (*params.Sigma < 0.3 || *params.Sigma > 1000) {
+		return url.Operation{}, fmt.Errorf("t.blur: s should be between 0.3 and 1000, got %v", *params.Sigma)
+	}
+	if params.Sigma != nil && *params.Sigma != 0.3 {
+		operation.Params = append(operation.Params, url.P("s", *params.Sigma))
+	}
+	return operation, nil
+}
+
+// TFlipParams are the params of t.flip, Flip.
+type TFlipParams struct {
+}
+
+// TFlipDefaults returns the params of t.flip set to their defaults
+func TFlipDefaults() TFlipParams {
+	return TFlipParams{}
+}
+
+// TFlip returns the t.flip operation, Mirror an image vertically.
+// Params equal to their default, nil and empty strings are left out of the url.
+func TFlip(params TFlipParams) (url.Operation, error) {
+	operation := url.Operation{Plugin: "t", Name: "flip"}
+	return operation, nil
+}
+
+// TToFormatFormat is the f param of t.toFormat
+type TToFormatFormat string
+
+const (
+	TToFormatFormatJpeg TToFormatFormat = "jpeg"
+	TToFormatFormatPng  TToFormatFormat = "png"
+	TToFormatFormatWebp TToFormatFormat = "webp"
+	TToFormatFormatAvif TToFormatFormat = "avif"
+)
+
+// TToFormatParams are the params of t.toFormat, Change format.
+type TToFormatParams struct {
+	// Format is f, Format, one of jpeg, png, webp, avif, defaults to "jpeg"
+	Format TToFormatFormat
+	// Progressive is p, Progressive, defaults to false
+	Progressive *bool
+}
+
+// TToFormatDefaults returns the params of t.toFormat set to their defaults
+func TToFormatDefaults() TToFormatParams {
+	return TToFormatParams{
+		Format:      "jpeg",
+		Progressive: Ptr[bool](false),
+	}
+}
+
+// TToFormat returns the t.toFormat operation, Convert an image to another format.
+// Params equal to their default, nil and empty strings are left out of the url.
+func TToFormat(params TToFormatParams) (url.Operation, error) {
+	operation := url.Operation{Plugin: "t", Name: "toFormat"}
+	switch params.Format {
+	case "", TToFormatFormatJpeg, TToFormatFormatPng, TToFormatFormatWebp, TToFormatFormatAvif:
+	default:
+		return url.Operation{}, fmt.Errorf("t.toFormat: invalid value %q for f", params.Format)
+	}
+	if params.Format != "" && params.Format != "jpeg" {
+		operation.Params = append(operation.Params, url.P("f", string(params.Format)))
+	}
+	if params.Progressive != nil && *params.Progressive != false {
+		operation.Params = append(operation.Params, url.P("p", *params.Progressive))
+	}
+	return operation, nil
+}
+
+// WmRemoveParams are the params of wm.remove, Remove watermark.
+type WmRemoveParams struct {
+	// RemoveText is rem_text, Remove text, defaults to false
+	RemoveText *bool
+}
+
+// WmRemoveDefaults returns the params of wm.remove set to their defaults
+func WmRemoveDefaults() WmRemoveParams {
+	return WmRemoveParams{
+		RemoveText: Ptr[bool](false),
+	}
+}
+
+// WmRemove returns the wm.remove operation, Remove watermarks from an image.
+// Params equal to their default, nil and empty strings are left out of the url.
+func WmRemove(params WmRemoveParams) (url.Operation, error) {
+	operation := url.Operation{Plugin: "wm", Name: "remove"}
+	if params.RemoveText != nil && *params.RemoveText != false {
+		operation.Params = append(operation.Params, url.P("rem_text", *params.RemoveText))
+	}
+	return operation, nil
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/common"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func TestPatchMetadata(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("catalog", "shoe", "jpeg", []byte("shoe"), nil, map[string]interface{}{
+		"sku":  "1",
+		"seo":  map[string]interface{}{"title": "Shoe", "keywords": "shoe"},
+		"tmp":  true,
+		"size": float64(42),
+	})
+	// another worker updates a different key between the read and the write
+	fake.concurrentEdits["catalog/shoe.jpeg"] = []func(file *fakeFile){
+		func(file *fakeFile) { file.Metadata["owner"] = "design" },
+	}
+
+	resp, err := fake.client().Assets.PatchMetadata(platform.PatchMetadataXQuery{
+		FileId: "catalog/shoe.jpeg",
+		Patch: map[string]interface{}{
+			"seo":  map[string]interface{}{"title": "Red shoe", "keywords": nil},
+			"tmp":  nil,
+			"size": float64(43),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	expected := map[string]interface{}{
+		"sku":   "1",
+		"seo":   map[string]interface{}{"title": "Red shoe"},
+		"size":  float64(43),
+		"owner": "design",
+	}
+	if !reflect.DeepEqual(fake.file("catalog/shoe.jpeg").Metadata, expected) {
+		t.Errorf("Failed ! expected %v, got %v", expected, fake.file("catalog/shoe.jpeg").Metadata)
+	}
+	if !reflect.DeepEqual(resp.Metadata, expected) {
+		t.Errorf("Failed ! unexpected response %v", resp.Metadata)
+	}
+	if fake.requestCount("GET /service/platform/assets/v1.0/files/") != 4 || fake.requestCount("PATCH") != 1 {
+		t.Errorf("Failed ! expected a single retry, got %v", fake.requests)
+	}
+}
+
+func TestAddAndRemoveTags(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("catalog", "shoe", "jpeg", []byte("shoe"), []string{"sale"}, nil)
+	fake.concurrentEdits["catalog/shoe.jpeg"] = []func(file *fakeFile){
+		func(file *fakeFile) { file.Tags = append(file.Tags, "summer") },
+	}
+	client := fake.client()
+
+	if _, err := client.Assets.AddTags(platform.EditTagsXQuery{FileId: "catalog/shoe.jpeg", Tags: []string{"new", "sale"}}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if tags := fake.file("catalog/shoe.jpeg").Tags; !reflect.DeepEqual(tags, []string{"sale", "summer", "new"}) {
+		t.Errorf("Failed ! unexpected tags %v", tags)
+	}
+
+	if _, err := client.Assets.RemoveTags(platform.EditTagsXQuery{FileId: "catalog/shoe.jpeg", Tags: []string{"sale", "new"}}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if tags := fake.file("catalog/shoe.jpeg").Tags; !reflect.DeepEqual(tags, []string{"summer"}) {
+		t.Errorf("Failed ! unexpected tags %v", tags)
+	}
+
+	patches := fake.requestCount("PATCH")
+	if _, err := client.Assets.RemoveTags(platform.EditTagsXQuery{FileId: "catalog/shoe.jpeg", Tags: []string{"sale"}}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if fake.requestCount("PATCH") != patches {
+		t.Errorf("Failed ! a file without changes was updated")
+	}
+}
+
+func TestPatchGivesUpOnConflicts(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("catalog", "shoe", "jpeg", []byte("shoe"), nil, nil)
+	edit := func(file *fakeFile) { file.Tags = append(file.Tags, "busy") }
+	fake.concurrentEdits["catalog/shoe.jpeg"] = []func(file *fakeFile){edit, edit, edit, edit, edit, edit}
+
+	retries := 2
+	_, err := fake.client().Assets.AddTags(platform.EditTagsXQuery{FileId: "catalog/shoe.jpeg", Tags: []string{"new"}, MaxRetries: &retries})
+	fdkErr, ok := err.(*common.FDKError)
+	if !ok || fdkErr.Status != 409 {
+		t.Fatalf("Failed ! expected a conflict, got %v", err)
+	}
+	if fake.requestCount("PATCH") != 0 {
+		t.Errorf("Failed ! a conflicting update was written")
+	}
+}
+
+func TestPatchWithoutRetries(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("catalog", "shoe", "jpeg", []byte("shoe"), nil, nil)
+	fake.concurrentEdits["catalog/shoe.jpeg"] = []func(file *fakeFile){func(file *fakeFile) { file.Tags = append(file.Tags, "busy") }}
+
+	retries := 0
+	_, err := fake.client().Assets.AddTags(platform.EditTagsXQuery{FileId: "catalog/shoe.jpeg", Tags: []string{"new"}, MaxRetries: &retries})
+	fdkErr, ok := err.(*common.FDKError)
+	if !ok || fdkErr.Status != 409 {
+		t.Fatalf("Failed ! expected a conflict without retries, got %v", err)
+	}
+	if tags := fake.file("catalog/shoe.jpeg").Tags; !reflect.DeepEqual(tags, []string{"busy"}) {
+		t.Errorf("Failed ! unexpected tags %v", tags)
+	}
+}
@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+func presetResolver(presets map[string]url.PresetDefinition, calls map[string]int) url.PresetResolver {
+	return func(name string) (*url.PresetDefinition, error) {
+		calls[name]++
+		preset, ok := presets[name]
+		if !ok {
+			return nil, url.ErrPresetNotFound
+		}
+		return &preset, nil
+	}
+}
+
+func TestExpandPresets(t *testing.T) {
+	calls := map[string]int{}
+	resolver := presetResolver(map[string]url.PresetDefinition{
+		"thumb": {
+			Transformation: "t.resize(w:{w},h:$h)~t.flip()",
+			Params: map[string]interface{}{
+				"w": map[string]interface{}{"type": "integer", "default": 200.0},
+				"h": map[string]interface{}{"type": "integer", "default": 100.0},
+			},
+		},
+		"card": {
+			Transformation: "p:thumb(w:{size})~t.blur(s:1)",
+			Params:         map[string]interface{}{"size": map[string]interface{}{"type": "integer"}},
+		},
+		"loop-a": {Transformation: "t.flop()~p:loop-b"},
+		"loop-b": {Transformation: "p:loop-a"},
+		"broken": {Transformation: "p:missing"},
+	}, calls)
+
+	cases := map[string]string{
+		"p:thumb(w:300)~t.grey()":        "t.resize(h:100,w:300)~t.flip()~t.grey()",
+		"p:thumb":                        "t.resize(h:100,w:200)~t.flip()",
+		"t.rotate(a:90)~p:card(size:50)": "t.rotate(a:90)~t.resize(h:100,w:50)~t.flip()~t.blur(s:1)",
+		"t.flip()":                       "t.flip()",
+	}
+	for pattern, expected := range cases {
+		parsed, _ := url.ParsePattern(pattern)
+		expanded, err := url.ExpandPresets(parsed, resolver)
+		if err != nil {
+			t.Errorf("Failed ! %s: got err %v", pattern, err)
+			continue
+		}
+		if expanded.Pattern() != expected {
+			t.Errorf("Failed ! %s: expected %s, got %s", pattern, expected, expanded.Pattern())
+		}
+	}
+
+	failures := []struct {
+		pattern string
+		chain   []string
+		message string
+	}{
+		{"p:loop-a", []string{"loop-a", "loop-b", "loop-a"}, "preset loop-a -> loop-b -> loop-a: presets reference each other in a cycle"},
+		{"p:broken", []string{"broken", "missing"}, "preset broken -> missing: preset not found"},
+		{"p:thumb(q:1)", []string{"thumb"}, "preset thumb: unknown param q"},
+		{"p:card", []string{"card"}, "preset card: no value for param size"},
+	}
+	for _, failure := range failures {
+		parsed, _ := url.ParsePattern(failure.pattern)
+		_, err := url.ExpandPresets(parsed, resolver)
+		var presetErr *url.PresetError
+		if !errors.As(err, &presetErr) {
+			t.Errorf("Failed ! %s: expected a PresetError, got %v", failure.pattern, err)
+			continue
+		}
+		if !reflect.DeepEqual(presetErr.Chain, failure.chain) || err.Error() != failure.message {
+			t.Errorf("Failed ! %s: unexpected error %v, chain %v", failure.pattern, err, presetErr.Chain)
+		}
+	}
+	parsed, _ := url.ParsePattern("p:broken")
+	if _, err := url.ExpandPresets(parsed, resolver); !errors.Is(err, url.ErrPresetNotFound) {
+		t.Errorf("Failed ! expected ErrPresetNotFound, got %v", err)
+	}
+
+	calls = map[string]int{}
+	cache := url.NewPresetCache(presetResolver(map[string]url.PresetDefinition{"grey-up": {Transformation: "t.grey()"}}, calls))
+	for i := 0; i < 3; i++ {
+		cache.Resolve("grey-up")
+		cache.Resolve("missing")
+	}
+	cache.Invalidate("grey-up")
+	cache.Resolve("grey-up")
+	if calls["grey-up"] != 2 || calls["missing"] != 3 {
+		t.Errorf("Failed ! unexpected resolver calls %v", calls)
+	}
+
+	// a resolver returning no definition and no error is treated as a missing preset
+	calls = map[string]int{}
+	cache = url.NewPresetCache(func(name string) (*url.PresetDefinition, error) {
+		calls[name]++
+		return nil, nil
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := url.ExpandPresets(parsed, cache.Resolve); !errors.Is(err, url.ErrPresetNotFound) {
+			t.Errorf("Failed ! expected ErrPresetNotFound for a nil definition, got %v", err)
+		}
+	}
+	if calls["broken"] != 2 {
+		t.Errorf("Failed ! expected a nil definition not to be cached, got %v", calls)
+	}
+}
+
+func TestUrlToObjPresetExpansion(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addPreset("thumb", "t.resize(w:{w})~t.flip()", map[string]interface{}{
+		"w": map[string]interface{}{"type": "integer", "default": 200},
+	})
+	pixelbin := fake.client()
+
+	for i := 0; i < 2; i++ {
+		obj, err := url.UrlToObj(
+			"https://cdn.pixelbin.io/v2/red-scene-95b6ea/p:thumb(w:120)~t.grey()/image.jpeg",
+			url.WithPresetExpansion(pixelbin.Assets.ResolvePreset),
+		)
+		if err != nil {
+			t.Fatalf("Failed ! got err %v", err)
+		}
+		expanded, _ := url.TransformationFromMaps(obj["transformations"])
+		if expanded.Pattern() != "t.resize(w:120)~t.flip()~t.grey()" || obj["pattern"] != "p:thumb(w:120)~t.grey()" {
+			t.Errorf("Failed ! unexpected obj %v", obj)
+		}
+	}
+	if count := fake.requestCount("GET " + assetsApi + "/presets/thumb"); count != 1 {
+		t.Errorf("Failed ! expected the preset to be fetched once, got %d", count)
+	}
+
+	_, err := url.UrlToObj("https://cdn.pixelbin.io/v2/red-scene-95b6ea/p:nope/image.jpeg", url.WithPresetExpansion(pixelbin.Assets.ResolvePreset))
+	if !errors.Is(err, url.ErrPresetNotFound) {
+		t.Errorf("Failed ! expected ErrPresetNotFound, got %v", err)
+	}
+
+	// presets changed through ApplyPresets are fetched again
+	plan, _ := pixelbin.Assets.PlanPresets(platform.PlanPresetsXQuery{Presets: []platform.PresetSpec{{PresetName: "thumb", Transformation: "t.flop()"}}})
+	pixelbin.Assets.ApplyPresets(platform.ApplyPresetsXQuery{Plan: plan})
+	definition, err := pixelbin.Assets.ResolvePreset("thumb")
+	if err != nil || definition.Transformation != "t.flop()" {
+		t.Errorf("Failed ! expected the updated preset, got %+v, err %v", definition, err)
+	}
+
+	// and so are presets changed through the preset endpoints
+	pixelbin.Assets.DeletePreset(platform.DeletePresetXQuery{PresetName: "thumb"})
+	if _, err := pixelbin.Assets.ResolvePreset("thumb"); !errors.Is(err, url.ErrPresetNotFound) {
+		t.Errorf("Failed ! expected the deleted preset to be missing, got %v", err)
+	}
+	pixelbin.Assets.AddPreset(platform.AddPresetXQuery{PresetName: "thumb", Transformation: "t.grey()"})
+	if definition, err := pixelbin.Assets.ResolvePreset("thumb"); err != nil || definition.Transformation != "t.grey()" {
+		t.Errorf("Failed ! expected the added preset, got %+v, err %v", definition, err)
+	}
+	before := fake.requestCount("GET " + assetsApi + "/presets/thumb")
+	pixelbin.Assets.UpdatePreset(platform.UpdatePresetXQuery{PresetName: "thumb", Archived: true})
+	pixelbin.Assets.ResolvePreset("thumb")
+	if count := fake.requestCount("GET " + assetsApi + "/presets/thumb"); count != before+1 {
+		t.Errorf("Failed ! expected the updated preset to be fetched again, got %d requests", count-before)
+	}
+}
+
+func TestApplyDiffDropsCachedPresetsOfTarget(t *testing.T) {
+	staging := newFakePixelbin()
+	defer staging.Close()
+	staging.addPreset("thumb", "t.flop()", nil)
+	production := newFakePixelbin()
+	defer production.Close()
+	production.addPreset("thumb", "t.flip()", nil)
+	production.addPreset("old", "t.grey()", nil)
+
+	source, target := staging.client(), production.client()
+	for _, name := range []string{"thumb", "old"} {
+		if _, err := target.Assets.ResolvePreset(name); err != nil {
+			t.Fatalf("Failed ! got err %v", err)
+		}
+	}
+	diff, err := source.Assets.Diff(platform.DiffXQuery{Target: target.Assets, IncludePresets: true})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	resp, err := source.Assets.ApplyDiff(platform.ApplyDiffXQuery{Diff: diff, Target: target.Assets, Delete: true})
+	if err != nil || resp.Applied != 2 {
+		t.Fatalf("Failed ! unexpected response %+v, err %v", resp, err)
+	}
+	if definition, err := target.Assets.ResolvePreset("thumb"); err != nil || definition.Transformation != "t.flop()" {
+		t.Errorf("Failed ! expected the replaced preset, got %+v, err %v", definition, err)
+	}
+	if _, err := target.Assets.ResolvePreset("old"); !errors.Is(err, url.ErrPresetNotFound) {
+		t.Errorf("Failed ! expected the removed preset to be missing, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+func TestExtractPreset(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	modules, _ := os.ReadFile("testdata/modules.json")
+	fake.setModules(modules)
+	pixelbin := fake.client()
+
+	result, err := pixelbin.Assets.ExtractPreset(platform.ExtractPresetXQuery{
+		Url:        "https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(h:200,w:300)~t.compress(q:70)/folder/image.jpeg",
+		PresetName: "card",
+		Params:     []platform.ExtractPresetParam{{Name: "width", Operation: 0, Key: "w"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed ! %v", err)
+	}
+	if result.Transformation != "t.resize(h:200,w:{width})~t.compress(q:70)" {
+		t.Errorf("Failed ! unexpected transformation %s", result.Transformation)
+	}
+	if !reflect.DeepEqual(result.Params, map[string]interface{}{"width": map[string]interface{}{"type": "integer", "default": 300}}) {
+		t.Errorf("Failed ! unexpected params %v", result.Params)
+	}
+	if result.Url != "https://cdn.pixelbin.io/v2/red-scene-95b6ea/p:card/folder/image.jpeg" {
+		t.Errorf("Failed ! unexpected url %s", result.Url)
+	}
+	if preset := fake.presets["card"]; preset == nil || preset["transformation"] != result.Transformation {
+		t.Errorf("Failed ! preset not created %v", preset)
+	}
+
+	// the rewritten url applies the same operations as the original
+	obj, err := url.UrlToObj(result.Url, url.WithPresetExpansion(pixelbin.Assets.ResolvePreset))
+	if err != nil {
+		t.Fatalf("Failed ! %v", err)
+	}
+	expanded, _ := url.TransformationFromMaps(obj["transformations"])
+	if expanded.Pattern() != "t.resize(h:200,w:300)~t.compress(q:70)" {
+		t.Errorf("Failed ! unexpected expansion %s", expanded.Pattern())
+	}
+
+	custom, err := pixelbin.Assets.ExtractPreset(platform.ExtractPresetXQuery{
+		Url:            "https://xyz.designify.media/v2/t.flip()~t.blur(s:0.5)/image.jpeg?dpr=2",
+		IsCustomDomain: true,
+		PresetName:     "soft",
+		Params:         []platform.ExtractPresetParam{{Name: "sigma", Operation: 1, Key: "s"}},
+		DryRun:         true,
+	})
+	if err != nil {
+		t.Fatalf("Failed ! %v", err)
+	}
+	if custom.Url != "https://xyz.designify.media/v2/p:soft/image.jpeg?dpr=2.0" {
+		t.Errorf("Failed ! unexpected url %s", custom.Url)
+	}
+	if custom.Params["sigma"].(map[string]interface{})["type"] != "float" || fake.presets["soft"] != nil {
+		t.Errorf("Failed ! unexpected dry run %+v", custom)
+	}
+
+	for _, invalid := range []platform.ExtractPresetXQuery{
+		{Url: "https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.flip()/image.jpeg"},
+		{PresetName: "x"},
+		{Url: "https://cdn.pixelbin.io/v2/red-scene-95b6ea/original/image.jpeg", PresetName: "x"},
+		{Url: "https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.flip()/image.jpeg", PresetName: "x", Params: []platform.ExtractPresetParam{{Name: "a", Operation: 1, Key: "w"}}},
+		{Url: "https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(w:1)/image.jpeg", PresetName: "x", Params: []platform.ExtractPresetParam{{Name: "a", Key: "h"}}},
+		{Url: "https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(w:1,h:2)/image.jpeg", PresetName: "x", Params: []platform.ExtractPresetParam{{Name: "a", Key: "w"}, {Name: "a", Key: "h"}}},
+		{Url: "https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.flip()/image.jpeg", PresetName: "card"},
+	} {
+		if _, err := pixelbin.Assets.ExtractPreset(invalid); err == nil {
+			t.Errorf("Failed ! expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestExtractPresetParamTypes(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	modules, _ := os.ReadFile("testdata/modules.json")
+	fake.setModules(modules)
+	assets := fake.client().Assets
+
+	extract := func(pattern string, operation int, key string) (interface{}, error) {
+		result, err := assets.ExtractPreset(platform.ExtractPresetXQuery{
+			Url:        "https://cdn.pixelbin.io/v2/red-scene-95b6ea/" + pattern + "/image.jpeg",
+			PresetName: "x",
+			Params:     []platform.ExtractPresetParam{{Name: "a", Operation: operation, Key: key}},
+			DryRun:     true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result.Params["a"], nil
+	}
+	// the type comes from the catalogue, colors made of digits stay strings
+	for pattern, expected := range map[string]map[string]interface{}{
+		"t.resize(b:000000)": {"type": "color", "default": "000000"},
+		"t.resize(w:007)":    {"type": "integer", "default": 7},
+		"t.resize(f:fill)":   {"type": "enum", "default": "fill", "enum": []string{"cover", "contain", "fill", "inside", "outside"}},
+		"t.blur(s:2)":        {"type": "float", "default": 2.0},
+		"t.toFormat(p:true)": {"type": "boolean", "default": true},
+		// operations missing from the catalogue keep values which do not read back the same as strings
+		"t.rotate(a:007)":  {"type": "string", "default": "007"},
+		"t.rotate(a:nan)":  {"type": "string", "default": "nan"},
+		"t.rotate(a:NaN)":  {"type": "string", "default": "NaN"},
+		"t.rotate(a:90)":   {"type": "integer", "default": 90},
+		"t.rotate(a:0.5)":  {"type": "float", "default": 0.5},
+		"t.rotate(a:true)": {"type": "boolean", "default": true},
+	} {
+		key := pattern[strings.Index(pattern, "(")+1 : strings.Index(pattern, ":")]
+		declaration, err := extract(pattern, 0, key)
+		if err != nil || !reflect.DeepEqual(declaration, expected) {
+			t.Errorf("Failed ! expected %v for %s, got %v, err %v", expected, pattern, declaration, err)
+		}
+	}
+	for _, pattern := range []string{"t.blur(s:nan)", "t.blur(s:inf)", "t.resize(w:wide)", "t.toFormat(p:yes)"} {
+		key := pattern[strings.Index(pattern, "(")+1 : strings.Index(pattern, ":")]
+		if declaration, err := extract(pattern, 0, key); err == nil {
+			t.Errorf("Failed ! expected an error for %s, got %v", pattern, declaration)
+		}
+	}
+}
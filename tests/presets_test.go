@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func TestParsePresetSpecs(t *testing.T) {
+	list, err := platform.ParsePresetSpecsJSON([]byte(`[{"presetName": "thumb", "transformation": "t.resize(w:100)", "params": {"w": {"type": "integer", "default": 100}}}]`))
+	if err != nil || len(list) != 1 || list[0].PresetName != "thumb" || list[0].Params["w"] == nil {
+		t.Errorf("Failed ! unexpected presets %+v, err %v", list, err)
+	}
+	object, err := platform.ParsePresetSpecsJSON([]byte(`{"presets": [{"presetName": "old", "transformation": "t.flip()", "archived": true}]}`))
+	if err != nil || len(object) != 1 || !object[0].Archived {
+		t.Errorf("Failed ! unexpected presets %+v, err %v", object, err)
+	}
+	for _, invalid := range []string{
+		`[{"presetName": "a", "transformation": "t.flip()"}, {"presetName": "a", "transformation": "t.flop()"}]`,
+		`[{"presetName": "a"}]`,
+		`[{"transformation": "t.flip()"}]`,
+		`[{"presetName": "a", "transformation": "flip"}]`,
+		`"presets"`,
+	} {
+		if _, err := platform.ParsePresetSpecsJSON([]byte(invalid)); err == nil {
+			t.Errorf("Failed ! expected an error for %s", invalid)
+		}
+	}
+	// a list which does not decode reports the list error, not the one of the object form
+	_, err = platform.ParsePresetSpecsJSON([]byte(` [{"presetName": 1}]`))
+	if err == nil || !strings.Contains(err.Error(), "presetName") {
+		t.Errorf("Failed ! expected the list decode error, got %v", err)
+	}
+}
+
+func TestPlanAndApplyPresets(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addPreset("keep", "t.flip()", nil)
+	fake.addPreset("change", "t.flip()", nil)
+	fake.addPreset("archive", "t.flop()", nil)
+	fake.addPreset("restore", "t.flop()", nil)
+	fake.presets["restore"]["archived"] = true
+	fake.addPreset("orphan", "t.grey()", nil)
+	pixelbin := fake.client()
+
+	declared := []platform.PresetSpec{
+		{PresetName: "keep", Transformation: "t.flip()"},
+		{PresetName: "change", Transformation: "t.resize(w:{w})", Params: map[string]interface{}{"w": map[string]interface{}{"type": "integer", "default": 200.0}}},
+		{PresetName: "archive", Transformation: "t.flop()", Archived: true},
+		{PresetName: "restore", Transformation: "t.flop()"},
+		{PresetName: "fresh", Transformation: "t.blur(s:2)", Archived: true},
+	}
+
+	plan, err := pixelbin.Assets.PlanPresets(platform.PlanPresetsXQuery{Presets: declared})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	expected := "~ archive archive\n~ update change (transformation, params)\n+ create fresh\n~ unarchive restore\n"
+	if plan.String() != expected {
+		t.Errorf("Failed ! expected plan\n%s, got\n%s", expected, plan.String())
+	}
+
+	plan, err = pixelbin.Assets.PlanPresets(platform.PlanPresetsXQuery{Presets: declared, Prune: true})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if plan.Creates != 1 || plan.Updates != 1 || plan.Archives != 1 || plan.Unarchives != 1 || plan.Deletes != 1 {
+		t.Errorf("Failed ! unexpected plan counts %+v", plan)
+	}
+
+	dryRun, err := pixelbin.Assets.ApplyPresets(platform.ApplyPresetsXQuery{Plan: plan, DryRun: true})
+	if err != nil || !dryRun.DryRun || dryRun.Applied != 5 {
+		t.Errorf("Failed ! unexpected dry run %+v, err %v", dryRun, err)
+	}
+	if count := fake.requestCount("POST") + fake.requestCount("PATCH") + fake.requestCount("DELETE"); count != 0 {
+		t.Errorf("Failed ! expected a dry run to change nothing, got %d requests", count)
+	}
+
+	result, err := pixelbin.Assets.ApplyPresets(platform.ApplyPresetsXQuery{Plan: plan})
+	if err != nil || result.Applied != 5 || len(result.Failures) != 0 {
+		t.Fatalf("Failed ! unexpected result %+v, err %v", result, err)
+	}
+	if _, ok := fake.presets["orphan"]; ok {
+		t.Errorf("Failed ! expected orphan to be deleted")
+	}
+	if fake.presets["change"]["transformation"] != "t.resize(w:{w})" || fake.presets["fresh"]["archived"] != true ||
+		fake.presets["archive"]["archived"] != true || fake.presets["restore"]["archived"] != false {
+		t.Errorf("Failed ! unexpected presets %v", fake.presets)
+	}
+
+	plan, err = pixelbin.Assets.PlanPresets(platform.PlanPresetsXQuery{Presets: declared, Prune: true})
+	if err != nil || len(plan.Entries) != 0 {
+		t.Errorf("Failed ! expected an empty plan after apply, got %v, err %v", plan, err)
+	}
+
+	failing := &platform.PresetPlan{Entries: []platform.PresetPlanEntry{
+		{PresetName: "ghost", Action: platform.DELETE},
+		{PresetName: "keep", Action: platform.ARCHIVE, Preset: &platform.PresetSpec{PresetName: "keep", Transformation: "t.flip()", Archived: true}},
+	}}
+	result, err = pixelbin.Assets.ApplyPresets(platform.ApplyPresetsXQuery{Plan: failing})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	failures := []string{}
+	for _, failure := range result.Failures {
+		failures = append(failures, failure.PresetName)
+	}
+	if result.Applied != 1 || !reflect.DeepEqual(failures, []string{"ghost"}) {
+		t.Errorf("Failed ! unexpected result %+v", result)
+	}
+
+	if _, err := pixelbin.Assets.ApplyPresets(platform.ApplyPresetsXQuery{}); err == nil {
+		t.Errorf("Failed ! expected an error without a plan")
+	}
+}
+
+func TestApplyPresetsRestoresPresetWhenAddFails(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addPreset("change", "t.flip()", nil)
+	fake.presets["change"]["archived"] = true
+	fake.rejectedTransformations["t.resize(w:300)"] = true
+	pixelbin := fake.client()
+
+	plan, err := pixelbin.Assets.PlanPresets(platform.PlanPresetsXQuery{Presets: []platform.PresetSpec{
+		{PresetName: "change", Transformation: "t.resize(w:300)", Archived: true},
+	}})
+	if err != nil || plan.Updates != 1 {
+		t.Fatalf("Failed ! unexpected plan %v, err %v", plan, err)
+	}
+	result, err := pixelbin.Assets.ApplyPresets(platform.ApplyPresetsXQuery{Plan: plan})
+	if err != nil || len(result.Failures) != 1 || !strings.Contains(result.Failures[0].Error, "the previous preset was restored") {
+		t.Fatalf("Failed ! expected a failure reporting the restore, got %+v, err %v", result, err)
+	}
+	if fake.presets["change"]["transformation"] != "t.flip()" || fake.presets["change"]["archived"] != true {
+		t.Errorf("Failed ! the previous preset was not restored, got %v", fake.presets["change"])
+	}
+}
+
+func TestPlanPresetsComparesNumbersByValue(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addPreset("thumb", "t.resize(w:{w})", map[string]interface{}{"w": map[string]interface{}{"type": "integer", "default": 200.0}})
+
+	// params decoded from YAML hold ints where the API returns float64
+	plan, err := fake.client().Assets.PlanPresets(platform.PlanPresetsXQuery{Presets: []platform.PresetSpec{
+		{PresetName: "thumb", Transformation: "t.resize(w:{w})", Params: map[string]interface{}{"w": map[string]interface{}{"type": "integer", "default": 200}}},
+	}})
+	if err != nil || len(plan.Entries) != 0 {
+		t.Errorf("Failed ! expected an empty plan, got %v, err %v", plan, err)
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func newTagsFixture() *fakePixelbin {
+	fake := newFakePixelbin()
+	fake.addFile("catalog", "shoe", "jpeg", []byte("shoe"), []string{"Sale", "summer"}, nil)
+	fake.addFile("catalog", "bag", "jpeg", []byte("bag"), []string{"sale", "Sale", "new"}, nil)
+	fake.addFile("catalog/kids", "hat", "png", []byte("hat!"), []string{"sale"}, nil)
+	fake.addFile("other", "logo", "png", []byte("logo"), []string{"brand"}, nil)
+	return fake
+}
+
+func TestListTags(t *testing.T) {
+	fake := newTagsFixture()
+	defer fake.Close()
+
+	tags, err := fake.client().Assets.ListTags(platform.ListTagsXQuery{Path: "catalog"})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	expected := []platform.TagUsage{
+		{Tag: "Sale", Files: 2, Bytes: 7},
+		{Tag: "sale", Files: 2, Bytes: 7},
+		{Tag: "new", Files: 1, Bytes: 3},
+		{Tag: "summer", Files: 1, Bytes: 4},
+	}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Failed ! expected %+v, got %+v", expected, tags)
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	fake := newTagsFixture()
+	defer fake.Close()
+	client := fake.client()
+
+	query := platform.MergeTagsXQuery{Tags: []string{"Sale", "sale"}, Into: "sale", DryRun: true}
+	resp, err := client.Assets.MergeTags(query)
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Changed != 2 || fake.requestCount("PATCH") != 0 {
+		t.Fatalf("Failed ! unexpected dry run %+v", resp)
+	}
+
+	query.DryRun = false
+	resp, err = client.Assets.MergeTags(query)
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Changed != 2 || resp.Failed != 0 || fake.requestCount("PATCH") != 2 {
+		t.Fatalf("Failed ! unexpected response %+v", resp)
+	}
+	if tags := fake.file("catalog/shoe.jpeg").Tags; !reflect.DeepEqual(tags, []string{"sale", "summer"}) {
+		t.Errorf("Failed ! unexpected tags %v", tags)
+	}
+	if tags := fake.file("catalog/bag.jpeg").Tags; !reflect.DeepEqual(tags, []string{"sale", "new"}) {
+		t.Errorf("Failed ! unexpected tags %v", tags)
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	fake := newTagsFixture()
+	defer fake.Close()
+	fake.failUpdates["catalog/kids/hat.png"] = true
+
+	resp, err := fake.client().Assets.RenameTag(platform.RenameTagXQuery{Path: "catalog", From: "sale", To: "promo"})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Changed != 1 || resp.Failed != 1 {
+		t.Fatalf("Failed ! unexpected response %+v", resp)
+	}
+	if tags := fake.file("catalog/bag.jpeg").Tags; !reflect.DeepEqual(tags, []string{"promo", "Sale", "new"}) {
+		t.Errorf("Failed ! unexpected tags %v", tags)
+	}
+	if _, err := fake.client().Assets.RenameTag(platform.RenameTagXQuery{From: "sale"}); err == nil {
+		t.Errorf("Failed ! expected an error for an empty tag")
+	}
+}
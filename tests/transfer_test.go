@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func TestMoveFolder(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("team", "logo", "png", []byte("logo"), nil, nil)
+	fake.addFile("team/nested", "banner", "jpeg", []byte("banner"), nil, nil)
+	fake.addFolder("team/empty")
+
+	progress := []platform.TransferProgress{}
+	resp, err := fake.client().Assets.Move(platform.MoveXQuery{
+		Source:      "team",
+		Destination: "archive/2024/team",
+		Progress:    func(p platform.TransferProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Files != 2 || resp.Folders != 2 || len(progress) != 2 {
+		t.Fatalf("Failed ! unexpected response %+v, progress %+v", resp, progress)
+	}
+	if fake.file("archive/2024/team/logo.png") == nil || fake.file("archive/2024/team/nested/banner.jpeg") == nil {
+		t.Errorf("Failed ! files were not moved, got %v", fake.fileIds())
+	}
+	if _, ok := fake.folders["archive/2024/team/empty"]; !ok {
+		t.Errorf("Failed ! empty folder was not moved")
+	}
+	if _, ok := fake.folders["team"]; ok {
+		t.Errorf("Failed ! source folder was not removed")
+	}
+}
+
+func TestMoveFolderKeepsFilesAddedDuringMove(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("team", "logo", "png", []byte("logo"), nil, nil)
+
+	resp, err := fake.client().Assets.Move(platform.MoveXQuery{
+		Source:      "team",
+		Destination: "moved",
+		Progress: func(platform.TransferProgress) {
+			fake.addFile("team", "late", "png", []byte("late"), nil, nil)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if !resp.SourceKept || len(resp.Remaining) != 1 || resp.Remaining[0] != "team/late.png" {
+		t.Errorf("Failed ! unexpected response %+v", resp)
+	}
+	if fake.file("team/late.png") == nil || fake.file("moved/logo.png") == nil {
+		t.Errorf("Failed ! unexpected files %v", fake.fileIds())
+	}
+	if _, ok := fake.folders["team"]; !ok {
+		t.Errorf("Failed ! source folder was deleted with a file in it")
+	}
+}
+
+func TestRenameFile(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("team", "logo", "png", []byte("logo"), nil, nil)
+
+	if _, err := fake.client().Assets.Rename(platform.RenameXQuery{Source: "team/logo.png", Name: "brand"}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if fake.file("team/brand.png") == nil || fake.file("team/logo.png") != nil {
+		t.Errorf("Failed ! file was not renamed, got %v", fake.fileIds())
+	}
+}
+
+func TestCopyFolder(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("team", "logo", "png", []byte("logo"), []string{"brand"}, map[string]interface{}{"owner": "design"})
+
+	resp, err := fake.client().Assets.Copy(platform.CopyXQuery{Source: "team", Destination: "shared/team"})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Files != 1 {
+		t.Fatalf("Failed ! unexpected response %+v", resp)
+	}
+	copied := fake.file("shared/team/logo.png")
+	if copied == nil || string(copied.content) != "logo" || copied.Tags[0] != "brand" || copied.Metadata["owner"] != "design" {
+		t.Errorf("Failed ! unexpected copy %+v", copied)
+	}
+	if fake.file("team/logo.png") == nil {
+		t.Errorf("Failed ! source file was removed")
+	}
+}
+
+func TestMoveRollsBackOnFailure(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("source", "first", "png", []byte("first"), nil, nil)
+	fake.addFile("source", "second", "png", []byte("second"), nil, nil)
+	fake.failUpdates["source/first.png"] = true
+
+	progress := []platform.TransferProgress{}
+	resp, err := fake.client().Assets.Move(platform.MoveXQuery{
+		Source:      "source",
+		Destination: "target",
+		Progress:    func(p platform.TransferProgress) { progress = append(progress, p) },
+	})
+	if err == nil {
+		t.Fatalf("Failed ! expected an error")
+	}
+	if !resp.RolledBack {
+		t.Errorf("Failed ! expected a rollback, got %+v", resp)
+	}
+	if fake.file("source/first.png") == nil || fake.file("source/second.png") == nil {
+		t.Errorf("Failed ! files were not restored, got %v", fake.fileIds())
+	}
+	if _, ok := fake.folders["target"]; ok {
+		t.Errorf("Failed ! created folder was not removed")
+	}
+	if last := progress[len(progress)-1]; last.Operation != platform.ROLLBACK {
+		t.Errorf("Failed ! expected rollback progress, got %+v", progress)
+	}
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func fakeContext(width, height int) map[string]interface{} {
+	return map[string]interface{}{
+		"steps": []interface{}{
+			map[string]interface{}{"name": "resize", "identifier": "t", "operation": "Basic", "format": "jpeg", "width": width, "height": height, "size": 1200, "density": 72},
+			map[string]interface{}{"name": "toFormat", "identifier": "t", "operation": "Basic", "format": "webp", "width": width, "height": height, "size": 800},
+		},
+		"metadata": map[string]interface{}{"width": 1140, "height": 760, "format": "jpeg", "contentType": "image/jpeg", "assetType": "image", "isImageAsset": true},
+		"headers":  map[string]interface{}{"Host": "api.pixelbin.io"},
+		"params":   map[string]interface{}{},
+		"extra":    "kept",
+	}
+}
+
+func TestGetContext(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.contexts["/v2/test-cloud/t.resize(w:300)~t.toFormat(f:webp)/a.jpeg"] = fakeContext(300, 200)
+	pixelbin := fake.client()
+
+	context, err := pixelbin.Transformation.GetContext(platform.GetTransformationContextXQuery{URL: "/v2/test-cloud/t.resize(w:300)~t.toFormat(f:webp)/a.jpeg"})
+	if err != nil {
+		t.Fatalf("Failed ! %v", err)
+	}
+	last, ok := context.LastStep()
+	if !ok || last.Format != "webp" || last.Width != 300 || last.Size != 800 {
+		t.Errorf("Failed ! unexpected last step %+v", last)
+	}
+	resize, ok := context.Step("t", "resize")
+	if !ok || resize.Format != "jpeg" || resize.Density != 72 {
+		t.Errorf("Failed ! unexpected resize step %+v", resize)
+	}
+	if _, ok := context.Step("t", "flip"); ok {
+		t.Errorf("Failed ! unexpected flip step")
+	}
+	if !context.Metadata.IsImageAsset || context.Metadata.Width != 1140 || context.Metadata.ContentType != "image/jpeg" {
+		t.Errorf("Failed ! unexpected metadata %+v", context.Metadata)
+	}
+	if context.Header("host") != "api.pixelbin.io" || context.Raw["extra"] != "kept" {
+		t.Errorf("Failed ! unexpected headers %v or raw %v", context.Headers, context.Raw)
+	}
+
+	if _, err := pixelbin.Transformation.GetContext(platform.GetTransformationContextXQuery{URL: "/v2/test-cloud/missing.jpeg"}); err == nil {
+		t.Errorf("Failed ! expected an error for a missing url")
+	}
+}
+
+func TestGetContexts(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.contexts["/v2/test-cloud/t.resize(w:100)/a.jpeg"] = fakeContext(100, 50)
+	fake.contexts["/v2/test-cloud/t.resize(w:200)/a.jpeg"] = fakeContext(200, 100)
+	pixelbin := fake.client()
+
+	urls := []string{
+		"/v2/test-cloud/t.resize(w:100)/a.jpeg",
+		"/v2/test-cloud/missing.jpeg",
+		"/v2/test-cloud/t.resize(w:200)/a.jpeg",
+		"/v2/test-cloud/t.resize(w:100)/a.jpeg",
+	}
+	results, err := pixelbin.Transformation.GetContexts(platform.GetTransformationContextsXQuery{URLs: urls, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("Failed ! %v", err)
+	}
+	if len(results) != len(urls) {
+		t.Fatalf("Failed ! expected %d results, got %d", len(urls), len(results))
+	}
+	for i, result := range results {
+		if result.URL != urls[i] {
+			t.Errorf("Failed ! result %d is for %s", i, result.URL)
+		}
+	}
+	widths := []int{100, 0, 200, 100}
+	for i, width := range widths {
+		if width == 0 {
+			if results[i].Error == "" || results[i].Context != nil {
+				t.Errorf("Failed ! expected an error for %s", urls[i])
+			}
+			continue
+		}
+		if results[i].Error != "" || results[i].Context == nil || results[i].Context.Steps[0].Width != width {
+			t.Errorf("Failed ! unexpected result %+v", results[i])
+		}
+	}
+	if count := fake.requestCount("GET /service/platform/transformation/context"); count != 3 {
+		t.Errorf("Failed ! expected 3 requests, got %d", count)
+	}
+
+	if _, err := pixelbin.Transformation.GetContexts(platform.GetTransformationContextsXQuery{URLs: []string{""}}); err == nil {
+		t.Errorf("Failed ! expected an error for an empty url")
+	}
+}
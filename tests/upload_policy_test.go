@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+)
+
+func TestUploadPolicy(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	client := fake.client()
+	err := client.Assets.SetUploadPolicy(&platform.UploadPolicy{
+		Access:   platform.PRIVATE,
+		Tags:     []string{"team-a"},
+		Metadata: map[string]interface{}{"uploadedBy": "{uploader}", "source": "{source}", "reviewed": false},
+		Path:     "uploads/{yyyy}/{mm}",
+		Naming:   platform.SLUG,
+		Uploader: "ci",
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+
+	local := filepath.Join(t.TempDir(), "Summer Sale Banner.png")
+	os.WriteFile(local, []byte("banner"), 0o644)
+	file, _ := os.Open(local)
+	defer file.Close()
+	_, err = client.Assets.FileUpload(platform.FileUploadXQuery{File: file, Tags: []string{"sale"}, Metadata: map[string]interface{}{"reviewed": true}})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+
+	now := time.Now().UTC()
+	fileId := "uploads/" + now.Format("2006") + "/" + now.Format("01") + "/summer-sale-banner.png"
+	uploaded := fake.file(fileId)
+	if uploaded == nil {
+		t.Fatalf("Failed ! expected %s, got %v", fileId, fake.fileIds())
+	}
+	if uploaded.Access != "private" || !reflect.DeepEqual(uploaded.Tags, []string{"team-a", "sale"}) {
+		t.Errorf("Failed ! unexpected access or tags %+v", uploaded)
+	}
+	expected := map[string]interface{}{"uploadedBy": "ci", "source": "Summer Sale Banner.png", "reviewed": true}
+	if !reflect.DeepEqual(uploaded.Metadata, expected) {
+		t.Errorf("Failed ! expected metadata %v, got %v", expected, uploaded.Metadata)
+	}
+
+	// values of the request take precedence over the policy
+	file.Seek(0, 0)
+	_, err = client.Assets.FileUpload(platform.FileUploadXQuery{File: file, Path: "manual", Name: "kept", Access: platform.PUBLIC_READ})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if manual := fake.file("manual/kept.png"); manual == nil || manual.Access != "public-read" {
+		t.Errorf("Failed ! unexpected files %v", fake.fileIds())
+	}
+
+	client.Assets.SetUploadPolicy(nil)
+	file.Seek(0, 0)
+	if _, err := client.Assets.FileUpload(platform.FileUploadXQuery{File: file}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if fake.file("Summer Sale Banner.png") == nil {
+		t.Errorf("Failed ! the policy was not removed, got %v", fake.fileIds())
+	}
+}
+
+func TestUploadPolicyNaming(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	client := fake.client()
+
+	local := filepath.Join(t.TempDir(), "banner.png")
+	os.WriteFile(local, []byte("banner"), 0o644)
+	file, _ := os.Open(local)
+	defer file.Close()
+	client.Assets.SetUploadPolicy(&platform.UploadPolicy{Naming: platform.CONTENT_HASH})
+	if _, err := client.Assets.FileUpload(platform.FileUploadXQuery{File: file}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	sum := sha256.Sum256([]byte("banner"))
+	hashed := fake.file(hex.EncodeToString(sum[:])[:32] + ".png")
+	if hashed == nil || string(hashed.content) != "banner" {
+		t.Errorf("Failed ! expected a content hash name, got %v", fake.fileIds())
+	}
+
+	// url uploads are named after the content of the url, not the url
+	if _, err := client.Assets.UrlUpload(platform.UrlUploadXQuery{URL: hashed.URL, Path: "copies"}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if fake.file("copies/"+hashed.FileId) == nil {
+		t.Errorf("Failed ! expected the content hash of the url, got %v", fake.fileIds())
+	}
+	// signed uploads have no content to hash
+	_, err := client.Assets.CreateSignedUrlV2(platform.CreateSignedUrlV2XQuery{Format: "jpeg"})
+	if err == nil || !strings.Contains(err.Error(), "content-hash") {
+		t.Errorf("Failed ! expected a content-hash error for a signed upload without a name, got %v", err)
+	}
+
+	client.Assets.SetUploadPolicy(&platform.UploadPolicy{Naming: platform.UUID, Path: "{format}"})
+	if _, err := client.Assets.UrlUpload(platform.UrlUploadXQuery{URL: fake.server.URL + "/v2/test-cloud/original/" + hashed.FileId}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	uuid := regexp.MustCompile(`^png/[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\.png$`)
+	matched := false
+	for _, fileId := range fake.fileIds() {
+		matched = matched || uuid.MatchString(fileId)
+	}
+	if !matched {
+		t.Errorf("Failed ! expected a uuid name, got %v", fake.fileIds())
+	}
+}
+
+func TestUploadPolicyValidation(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	assets := fake.client().Assets
+
+	for _, policy := range []platform.UploadPolicy{
+		{Path: "uploads/{year}"},
+		{Metadata: map[string]interface{}{"by": "{who}"}},
+		{Naming: "random"},
+		{Access: "public"},
+	} {
+		if err := assets.SetUploadPolicy(&policy); err == nil {
+			t.Errorf("Failed ! expected policy %+v to be rejected", policy)
+		}
+	}
+}
+
+func TestUploadPolicySkipsInternalUploads(t *testing.T) {
+	source := newFakePixelbin()
+	defer source.Close()
+	source.addFile("", "logo", "png", []byte("logo-bytes"), []string{"brand"}, map[string]interface{}{"owner": "design"})
+	destination := t.TempDir()
+	if _, err := source.client().Assets.Backup(platform.BackupXQuery{Destination: destination}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+
+	target := newFakePixelbin()
+	defer target.Close()
+	client := target.client()
+	err := client.Assets.SetUploadPolicy(&platform.UploadPolicy{
+		Tags:     []string{"team-a"},
+		Metadata: map[string]interface{}{"source": "{source}"},
+		Path:     "uploads/{yyyy}",
+		Naming:   platform.UUID,
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if _, err := client.Assets.Restore(platform.RestoreXQuery{Source: destination}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if _, err := client.Assets.Copy(platform.CopyXQuery{Source: "logo.png", Destination: "copies/logo.png"}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+
+	if fileIds := target.fileIds(); !reflect.DeepEqual(fileIds, []string{"copies/logo.png", "logo.png"}) {
+		t.Fatalf("Failed ! the policy changed the path or name, got %v", fileIds)
+	}
+	for _, fileId := range []string{"logo.png", "copies/logo.png"} {
+		file := target.file(fileId)
+		if !reflect.DeepEqual(file.Tags, []string{"brand"}) || !reflect.DeepEqual(file.Metadata, map[string]interface{}{"owner": "design"}) {
+			t.Errorf("Failed ! the policy changed the tags or metadata of %s: %v %v", fileId, file.Tags, file.Metadata)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/cache"
+)
+
+var canonicalKeyCases = []struct {
+	scenario string
+	url      string
+	key      string
+}{
+	{
+		scenario: "Should keep urls without query untouched",
+		url:      "https://cdn.pixelbin.io/v2/dummy-cloudname/original/a.jpeg",
+		key:      "https://cdn.pixelbin.io/v2/dummy-cloudname/original/a.jpeg",
+	},
+	{
+		scenario: "Should strip signature params",
+		url:      "https://cdn.pixelbin.io/v2/dummy-cloudname/original/a.jpeg?pbs=abc&pbe=1696403372&pbt=2583",
+		key:      "https://cdn.pixelbin.io/v2/dummy-cloudname/original/a.jpeg",
+	},
+	{
+		scenario: "Should sort remaining params and lowercase the host",
+		url:      "https://CDN.pixelbin.io/v2/dummy-cloudname/t.resize(w:100)/a.jpeg?f_auto=true&pbs=abc&dpr=2.0",
+		key:      "https://cdn.pixelbin.io/v2/dummy-cloudname/t.resize(w:100)/a.jpeg?dpr=2.0&f_auto=true",
+	},
+}
+
+func TestCanonicalKey(t *testing.T) {
+	for _, testcase := range canonicalKeyCases {
+		t.Run(testcase.scenario, func(t *testing.T) {
+			key, err := cache.CanonicalKey(testcase.url)
+			if err != nil {
+				t.Fatalf("Failed ! got err %v", err)
+			}
+			if key != testcase.key {
+				t.Errorf("Failed ! expected %s, got %s", testcase.key, key)
+			}
+		})
+	}
+}
+
+func putCacheEntry(t *testing.T, diskCache *cache.DiskCache, key string, content string) {
+	writer, err := diskCache.NewWriter(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]byte(content))
+	if err := writer.Commit(cache.Entry{ETag: `"` + key + `"`}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiskCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	diskCache, err := cache.NewDiskCache(dir, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	putCacheEntry(t, diskCache, "a", "aaaa")
+	putCacheEntry(t, diskCache, "b", "bbbb")
+	content, _, err := diskCache.Open("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content.Close()
+	putCacheEntry(t, diskCache, "c", "cccc")
+
+	if _, ok := diskCache.Get("b"); ok {
+		t.Errorf("Failed ! expected b to be evicted")
+	}
+	if _, ok := diskCache.Get("a"); !ok {
+		t.Errorf("Failed ! expected a to be kept")
+	}
+	if diskCache.Size() != 8 {
+		t.Errorf("Failed ! expected size 8, got %d", diskCache.Size())
+	}
+
+	reopened, err := cache.NewDiskCache(dir, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 2 {
+		t.Errorf("Failed ! expected 2 entries after reopening, got %d", reopened.Len())
+	}
+}
+
+func TestDownloadThroughCache(t *testing.T) {
+	server, requested := newDownloadServer()
+	defer server.Close()
+	client := newTestClient(server.URL)
+	diskCache, err := cache.NewDiskCache(t.TempDir(), 1<<20, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Assets.SetDownloadCache(diskCache)
+
+	assetUrl := server.URL + "/v2/test-cloud/original/dir/asset.jpeg"
+	for i, token := range []string{"first-token", "second-token"} {
+		var buf bytes.Buffer
+		resp, err := client.Assets.Download(platform.DownloadXQuery{URL: assetUrl, Writer: &buf, SignToken: token, SignTokenID: 1})
+		if err != nil {
+			t.Fatalf("Failed ! got err %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), downloadContent) {
+			t.Errorf("Failed ! expected %q got %q", downloadContent, buf.Bytes())
+		}
+		if resp.FromCache != (i == 1) {
+			t.Errorf("Failed ! download %d unexpected FromCache %v", i, resp.FromCache)
+		}
+	}
+	if len(*requested) != 1 {
+		t.Errorf("Failed ! expected a single request, got %v", *requested)
+	}
+}
+
+func TestDownloadRevalidatesStaleEntries(t *testing.T) {
+	server, requested := newDownloadServer()
+	defer server.Close()
+	client := newTestClient(server.URL)
+	diskCache, err := cache.NewDiskCache(t.TempDir(), 1<<20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Assets.SetDownloadCache(diskCache)
+
+	assetUrl := server.URL + "/v2/test-cloud/original/dir/asset.jpeg"
+	client.Assets.Download(platform.DownloadXQuery{URL: assetUrl, Writer: &bytes.Buffer{}})
+	var buf bytes.Buffer
+	resp, err := client.Assets.Download(platform.DownloadXQuery{URL: assetUrl, Writer: &buf})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.StatusCode != http.StatusNotModified || !resp.FromCache || !bytes.Equal(buf.Bytes(), downloadContent) {
+		t.Errorf("Failed ! unexpected response %+v", resp)
+	}
+	if len(*requested) != 2 || !strings.HasSuffix((*requested)[1], "asset.jpeg") {
+		t.Errorf("Failed ! expected a revalidation request, got %v", *requested)
+	}
+}
+
+func TestDownloadRefetchesEvictedEntries(t *testing.T) {
+	var diskCache *cache.DiskCache
+	requested := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") != "" {
+			// the entry is evicted while the revalidation is in flight
+			key, _ := cache.CanonicalKey("http://" + r.Host + r.URL.RequestURI())
+			diskCache.Remove(key)
+		}
+		w.Header().Set("ETag", `"etag-1"`)
+		http.ServeContent(w, r, "asset.jpeg", testModTime, bytes.NewReader(downloadContent))
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL)
+	var err error
+	diskCache, err = cache.NewDiskCache(t.TempDir(), 1<<20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Assets.SetDownloadCache(diskCache)
+
+	assetUrl := server.URL + "/v2/test-cloud/original/dir/asset.jpeg"
+	client.Assets.Download(platform.DownloadXQuery{URL: assetUrl, Writer: &bytes.Buffer{}})
+	var buf bytes.Buffer
+	resp, err := client.Assets.Download(platform.DownloadXQuery{URL: assetUrl, Writer: &buf})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.FromCache || !bytes.Equal(buf.Bytes(), downloadContent) {
+		t.Errorf("Failed ! unexpected response %+v", resp)
+	}
+	if len(requested) != 3 || requested[1] == "" || requested[2] != "" {
+		t.Errorf("Failed ! expected a revalidation followed by an unconditional request, got %q", requested)
+	}
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/codegen"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+	"github.com/pixelbin-dev/pixelbin-go/v2/tests/operations"
+)
+
+func loadCatalogue(t *testing.T) *url.Catalogue {
+	data, err := os.ReadFile("testdata/modules.json")
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	catalogue, err := url.ParseCatalogue(data)
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	return catalogue
+}
+
+func TestParseCatalogue(t *testing.T) {
+	catalogue := loadCatalogue(t)
+	if ids := strings.Join(catalogue.PluginIds(), ","); ids != "erase,t,wm" {
+		t.Errorf("Failed ! expected erase,t,wm, got %s", ids)
+	}
+	// a single param object is read as a list of one param
+	bg, ok := catalogue.Operation("erase", "bg")
+	if !ok || len(bg.Params) != 1 || bg.Params[0].Identifier != "i" || len(bg.Params[0].Enum) != 2 {
+		t.Errorf("Failed ! unexpected erase.bg %+v", bg)
+	}
+	resize, _ := catalogue.Operation("t", "resize")
+	width, ok := resize.Param("w")
+	if !ok || width.Type != url.ParamInteger || *width.Max != 10000 {
+		t.Errorf("Failed ! unexpected t.resize w %+v", width)
+	}
+	if _, ok := catalogue.Operation("t", "resise"); ok {
+		t.Errorf("Failed ! expected t.resise to be missing")
+	}
+	if catalogue.Plugins["wm"].Enabled {
+		t.Errorf("Failed ! expected wm to be disabled")
+	}
+
+	fromModules, err := url.CatalogueFromModules(map[string]interface{}{
+		"plugins": map[string]interface{}{"t": map[string]interface{}{"operations": []interface{}{}}},
+	})
+	if err != nil || fromModules.Plugins["t"].Identifier != "t" {
+		t.Errorf("Failed ! unexpected catalogue %+v, err %v", fromModules, err)
+	}
+	if _, err := url.ParseCatalogue([]byte(`{"presets": []}`)); err == nil {
+		t.Errorf("Failed ! expected an error for a catalogue without plugins")
+	}
+}
+
+func TestCodegenGenerate(t *testing.T) {
+	src, err := codegen.Generate(loadCatalogue(t), "ops")
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if !strings.HasPrefix(string(src), "// Code generated by pixelbin-gen. DO NOT EDIT.") {
+		t.Errorf("Failed ! expected a generated code header")
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "ops.go", src, 0)
+	if err != nil {
+		t.Fatalf("Failed ! generated source does not parse: %v", err)
+	}
+	if file.Name.Name != "ops" {
+		t.Errorf("Failed ! expected package ops, got %s", file.Name.Name)
+	}
+	declared := map[string]bool{}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			declared[d.Name.Name] = true
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					declared[s.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						declared[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	for _, name := range []string{
+		"EraseBg", "EraseBgParams", "EraseBgDefaults", "EraseBgIndustryType", "EraseBgIndustryTypeEcommerce",
+		"TResize", "TResizeParams", "TResizeFit", "TResizePositionRightTop", "TFlip", "TToFormat", "WmRemove",
+	} {
+		if !declared[name] {
+			t.Errorf("Failed ! expected %s to be generated", name)
+		}
+	}
+
+	for _, expected := range []string{
+		"\tWidth *int\n",
+		"\tSigma *float64\n",
+		"\tRemoveText *bool\n",
+		`Quality: Ptr[int](80),`,
+		`fmt.Errorf("t.resize: w should be between 0 and 10000, got %v", *params.Width)`,
+		`operation.Params = append(operation.Params, url.P("f", string(params.Fit)))`,
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("Failed ! expected generated source to contain %s", expected)
+		}
+	}
+
+	if _, err := codegen.Generate(&url.Catalogue{}, "ops"); err == nil {
+		t.Errorf("Failed ! expected an error for an empty catalogue")
+	}
+}
+
+func TestCodegenGeneratedPackage(t *testing.T) {
+	src, err := codegen.Generate(loadCatalogue(t), "operations")
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	generated, err := os.ReadFile("operations/operations.go")
+	if err != nil || string(generated) != string(src) {
+		t.Fatalf("Failed ! operations/operations.go is out of date, run go generate ./tests/operations")
+	}
+
+	// the zero value of the params leaves every param out of the url
+	compress, err := operations.TCompress(operations.TCompressParams{})
+	if err != nil || len(compress.Params) != 0 {
+		t.Errorf("Failed ! unexpected operation %+v, err %v", compress, err)
+	}
+	blur, err := operations.TBlur(operations.TBlurParams{})
+	if err != nil || len(blur.Params) != 0 {
+		t.Errorf("Failed ! unexpected operation %+v, err %v", blur, err)
+	}
+	compress, err = operations.TCompress(operations.TCompressDefaults())
+	if err != nil || len(compress.Params) != 0 {
+		t.Errorf("Failed ! unexpected operation %+v, err %v", compress, err)
+	}
+	compress, err = operations.TCompress(operations.TCompressParams{Quality: operations.Ptr(60)})
+	if err != nil || len(compress.Params) != 1 || compress.Params[0].Value != "60" {
+		t.Errorf("Failed ! unexpected operation %+v, err %v", compress, err)
+	}
+	// an explicit 0 is a value like any other, not the server default
+	if _, err := operations.TCompress(operations.TCompressParams{Quality: operations.Ptr(0)}); err == nil {
+		t.Errorf("Failed ! expected an error for a quality of 0")
+	}
+	if _, err := operations.TCompress(operations.TCompressParams{Quality: operations.Ptr(101)}); err == nil {
+		t.Errorf("Failed ! expected an error for a quality out of range")
+	}
+	if _, err := operations.TBlur(operations.TBlurParams{Sigma: operations.Ptr(-1.0)}); err == nil {
+		t.Errorf("Failed ! expected an error for a sigma out of range")
+	}
+	format, err := operations.TToFormat(operations.TToFormatParams{Progressive: operations.Ptr(true)})
+	if err != nil || len(format.Params) != 1 || format.Params[0].Value != "true" {
+		t.Errorf("Failed ! unexpected operation %+v, err %v", format, err)
+	}
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/metadata"
+)
+
+type productDimensions struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type productMetadata struct {
+	SKU        string            `json:"sku"`
+	Price      float64           `json:"price"`
+	Stock      uint              `json:"stock,omitempty"`
+	Colors     []string          `json:"colors"`
+	Dimensions productDimensions `json:"dimensions"`
+	Labels     map[string]int    `json:"labels,omitempty"`
+	Launch     *time.Time        `json:"launch"`
+	Internal   string            `json:"-"`
+}
+
+func TestMetadataDecode(t *testing.T) {
+	decoded, err := metadata.Decode[productMetadata](map[string]interface{}{
+		"sku":        "SHOE-1",
+		"price":      12.5,
+		"stock":      float64(3),
+		"colors":     []interface{}{"red", "blue"},
+		"dimensions": map[string]interface{}{"width": float64(200), "height": float64(100)},
+		"launch":     "2024-03-01T00:00:00Z",
+		"extra":      true,
+	})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	launch := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	expected := productMetadata{
+		SKU:        "SHOE-1",
+		Price:      12.5,
+		Stock:      3,
+		Colors:     []string{"red", "blue"},
+		Dimensions: productDimensions{Width: 200, Height: 100},
+		Launch:     &launch,
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Failed ! expected %+v, got %+v", expected, decoded)
+	}
+}
+
+func TestMetadataDecodeValidation(t *testing.T) {
+	_, err := metadata.Decode[productMetadata](map[string]interface{}{
+		"price":      "12.5",
+		"stock":      float64(-1),
+		"colors":     []interface{}{"red", float64(2)},
+		"dimensions": map[string]interface{}{"width": 1.5},
+		"labels":     map[string]interface{}{"b": "x", "a": float64(1)},
+		"launch":     "yesterday",
+	})
+	var validationErr *metadata.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Failed ! expected a validation error, got %v", err)
+	}
+	fields := []string{}
+	for _, fieldErr := range validationErr.Errors {
+		fields = append(fields, fieldErr.Field)
+	}
+	expected := []string{"sku", "price", "stock", "colors[1]", "dimensions.width", "dimensions.height", "labels.b", "launch"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Failed ! expected errors on %v, got %v", expected, err)
+	}
+	if validationErr.Errors[1].Message != "expected number, got string" {
+		t.Errorf("Failed ! unexpected message %q", validationErr.Errors[1].Message)
+	}
+
+	if _, err := metadata.Decode[string](nil); err == nil {
+		t.Errorf("Failed ! expected an error for a non struct type")
+	}
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFile("catalog", "shoe", "jpeg", []byte("shoe"), nil, nil)
+	client := fake.client()
+
+	original := productMetadata{SKU: "SHOE-1", Price: 10, Colors: []string{"red"}, Dimensions: productDimensions{Width: 1, Height: 2}}
+	encoded, err := metadata.Encode(original)
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if _, err := client.Assets.UpdateFile(platform.UpdateFileXQuery{FileId: "catalog/shoe.jpeg", Metadata: encoded}); err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	resp, err := client.Assets.GetFileByFileId(platform.GetFileByFileIdXQuery{FileId: "catalog/shoe.jpeg"})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	decoded, err := metadata.Decode[productMetadata](resp["metadata"].(map[string]interface{}))
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if !reflect.DeepEqual(decoded, original) {
+		t.Errorf("Failed ! expected %+v, got %+v", original, decoded)
+	}
+
+	if _, err := metadata.Encode([]string{"a"}); err == nil {
+		t.Errorf("Failed ! expected an error for metadata that is not an object")
+	}
+}
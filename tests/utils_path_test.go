@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/platform"
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/path"
+)
+
+func TestPathJoinAndSplit(t *testing.T) {
+	if joined := path.Join("/a/", "", "b//c", "d/"); joined != "a/b/c/d" {
+		t.Errorf("Failed ! expected a/b/c/d, got %s", joined)
+	}
+	if normalized := path.Normalize("///"); normalized != "" {
+		t.Errorf("Failed ! expected the root folder, got %q", normalized)
+	}
+	parent, name := path.Split("/a/b/c/")
+	if parent != "a/b" || name != "c" {
+		t.Errorf("Failed ! unexpected split %q %q", parent, name)
+	}
+	if rebased := path.Rebase("team/nested/a", "team", "archive/team"); rebased != "archive/team/nested/a" {
+		t.Errorf("Failed ! unexpected rebase %s", rebased)
+	}
+	if !path.IsWithin("team/nested", "team") || path.IsWithin("teams", "team") {
+		t.Errorf("Failed ! unexpected IsWithin results")
+	}
+}
+
+var fileIdCases = []struct {
+	fileId string
+	path   string
+	name   string
+	format string
+}{
+	{fileId: "dir/sub/asset.jpeg", path: "dir/sub", name: "asset", format: "jpeg"},
+	{fileId: "asset.tar.gz", path: "", name: "asset.tar", format: "gz"},
+	{fileId: "dir/.hidden", path: "dir", name: ".hidden", format: ""},
+}
+
+func TestFileId(t *testing.T) {
+	for _, testcase := range fileIdCases {
+		folderPath, name, format := path.ParseFileId(testcase.fileId)
+		if folderPath != testcase.path || name != testcase.name || format != testcase.format {
+			t.Errorf("Failed ! %s parsed as %q %q %q", testcase.fileId, folderPath, name, format)
+		}
+		if fileId := path.FileId(folderPath, name, format); fileId != testcase.fileId {
+			t.Errorf("Failed ! expected %s, got %s", testcase.fileId, fileId)
+		}
+	}
+}
+
+func TestValidatePath(t *testing.T) {
+	for _, valid := range []string{"", "/", "dir/sub", "/dir/my asset/"} {
+		if err := path.Validate(valid); err != nil {
+			t.Errorf("Failed ! expected %q to be valid, got %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"dir//sub", "dir/../sub", "dir/ sub", "dir/a\tb"} {
+		if err := path.Validate(invalid); err == nil {
+			t.Errorf("Failed ! expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestEnsureFolder(t *testing.T) {
+	fake := newFakePixelbin()
+	defer fake.Close()
+	fake.addFolder("a")
+	fake.racedFolders["a/b/c"] = true
+	client := fake.client()
+
+	resp, err := client.Assets.EnsureFolder(platform.EnsureFolderXQuery{Path: "/a/b/c/d/"})
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if resp.Path != "a/b/c/d" || len(resp.Created) != 2 || resp.Created[0].Name != "b" || resp.Created[1].Name != "d" {
+		t.Errorf("Failed ! unexpected response %+v", resp)
+	}
+	if _, ok := fake.folders["a/b/c/d"]; !ok {
+		t.Errorf("Failed ! folder was not created")
+	}
+
+	resp, err = client.Assets.EnsureFolder(platform.EnsureFolderXQuery{Path: "a/b/c/d"})
+	if err != nil || len(resp.Created) != 0 {
+		t.Errorf("Failed ! expected nothing to be created, got %+v, err %v", resp, err)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	for input, expected := range map[string]string{
+		"Summer Sale Banner": "summer-sale-banner",
+		"  --Déjà vu!! 2024": "déjà-vu-2024",
+		"already-a-slug":     "already-a-slug",
+		"***":                "",
+	} {
+		if slug := path.Slug(input); slug != expected {
+			t.Errorf("Failed ! expected %q for %q, got %q", expected, input, slug)
+		}
+	}
+}
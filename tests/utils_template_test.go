@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+func TestUrlTemplate(t *testing.T) {
+	catalogue := loadCatalogue(t)
+	resize, _ := catalogue.Operation("t", "resize")
+	width, _ := resize.Param("w")
+	format, _ := resize.Param("f")
+	template, err := url.NewTemplate(
+		"https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(w:{width},h:{height},f:{fit})~t.compress(q:80)/folder/image.jpeg?dpr=2",
+		map[string]url.ParamSpec{"width": width, "height": {Type: url.ParamInteger}, "fit": format},
+	)
+	if err != nil {
+		t.Fatalf("Failed ! %v", err)
+	}
+	if got := strings.Join(template.Placeholders(), ","); got != "fit,height,width" {
+		t.Errorf("Failed ! unexpected placeholders %s", got)
+	}
+
+	rendered, err := template.Render(map[string]interface{}{"width": 300, "height": "200"})
+	if err != nil || rendered != "https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(f:cover,h:200,w:300)~t.compress(q:80)/folder/image.jpeg?dpr=2.0" {
+		t.Errorf("Failed ! unexpected url %s, err %v", rendered, err)
+	}
+
+	type size struct {
+		Width  int
+		Height *int
+		Fit    string `pixelbin:"fit"`
+		Other  string
+	}
+	height := 50
+	rendered, err = template.RenderStruct(&size{Width: 100, Height: &height, Fit: "contain"})
+	if err != nil || rendered != "https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(f:contain,h:50,w:100)~t.compress(q:80)/folder/image.jpeg?dpr=2.0" {
+		t.Errorf("Failed ! unexpected url %s, err %v", rendered, err)
+	}
+	if _, err := template.RenderStruct(size{Width: 100}); err == nil || !strings.Contains(err.Error(), "height") {
+		t.Errorf("Failed ! expected a missing height, got %v", err)
+	}
+
+	for _, values := range []map[string]interface{}{
+		{"width": 300},
+		{"width": 20000, "height": 1},
+		{"width": "abc", "height": 1},
+		{"width": 1, "height": 1, "fit": "stretch"},
+		{"width": 1, "height": 1, "other": 1},
+		{"width": 1, "height": "1)~t.flip("},
+	} {
+		if _, err := template.Render(values); err == nil {
+			t.Errorf("Failed ! expected an error for %v", values)
+		}
+	}
+
+	custom, err := url.NewTemplate(
+		"https://xyz.designify.media/v2/p:card(text:{text})/image.jpeg",
+		map[string]url.ParamSpec{"text": {Type: "string", Default: "hello"}},
+		url.WithCustomDomain(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed ! %v", err)
+	}
+	if rendered, err := custom.Render(nil); err != nil || rendered != "https://xyz.designify.media/v2/p:card(text:hello)/image.jpeg" {
+		t.Errorf("Failed ! unexpected url %s, err %v", rendered, err)
+	}
+
+	for name, invalid := range map[string]map[string]url.ParamSpec{
+		"https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(w:{width})/image.jpeg": {},
+		"https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(w:300)/image.jpeg":     {"width": width},
+		"https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(w:{width})/other.jpeg": {"width": {Type: url.ParamInteger, Default: "wide"}},
+		"https://xyz.designify.media/t.resize(w:{width})/image.jpeg":                 {"width": width},
+	} {
+		if _, err := url.NewTemplate(name, invalid); err == nil {
+			t.Errorf("Failed ! expected an error for %s", name)
+		}
+	}
+}
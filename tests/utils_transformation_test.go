@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+func TestTransformationBuilder(t *testing.T) {
+	transformation := url.NewTransformation().
+		Resize(url.ResizeOptions{Width: 300, Height: 200, Fit: "cover"}).
+		Blur(0.5).
+		Op("erase", "bg", url.P("shadow", true)).
+		Preset("thumbnail")
+
+	pattern := "t.resize(f:cover,h:200,w:300)~t.blur(s:0.5)~erase.bg(shadow:true)~p:thumbnail"
+	if transformation.Pattern() != pattern {
+		t.Errorf("Failed ! expected %s, got %s", pattern, transformation.Pattern())
+	}
+	if url.NewTransformation().Pattern() != "original" {
+		t.Errorf("Failed ! expected original for an empty transformation")
+	}
+
+	cases := []struct {
+		options  url.URLOptions
+		expected string
+	}{
+		{
+			options:  url.URLOptions{CloudName: "red-scene-95b6ea"},
+			expected: "https://cdn.pixelbin.io/v2/red-scene-95b6ea/" + pattern + "/path/image.jpeg",
+		},
+		{
+			options:  url.URLOptions{CloudName: "red-scene-95b6ea", Zone: "z-slug", DPR: 2, FAuto: true},
+			expected: "https://cdn.pixelbin.io/v2/red-scene-95b6ea/z-slug/" + pattern + "/path/image.jpeg?dpr=2.0&f_auto=true",
+		},
+		{
+			options:  url.URLOptions{CustomDomain: "https://assets.example.com/"},
+			expected: "https://assets.example.com/v2/" + pattern + "/path/image.jpeg",
+		},
+	}
+	for _, tc := range cases {
+		generated, err := transformation.URL("/path/image.jpeg", tc.options)
+		if err != nil {
+			t.Fatalf("Failed ! got err %v", err)
+		}
+		if generated != tc.expected {
+			t.Errorf("Failed ! expected %s, got %s", tc.expected, generated)
+		}
+	}
+	if _, err := transformation.URL("image.jpeg", url.URLOptions{}); err == nil {
+		t.Errorf("Failed ! expected an error without a cloud name")
+	}
+}
+
+func TestTransformationMaps(t *testing.T) {
+	obj, err := url.UrlToObj("https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(h:200,w:300)~t.flip()~p:preset1(a:12)/image.jpeg")
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	transformation, err := url.TransformationFromMaps(obj["transformations"])
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	expected := url.NewTransformation().
+		Resize(url.ResizeOptions{Height: 200, Width: 300}).
+		Flip().
+		Preset("preset1", url.P("a", 12))
+	if !reflect.DeepEqual(transformation, expected) {
+		t.Errorf("Failed ! expected %+v, got %+v", expected, transformation)
+	}
+	if !reflect.DeepEqual(transformation.Maps(), obj["transformations"]) {
+		t.Errorf("Failed ! expected %v, got %v", obj["transformations"], transformation.Maps())
+	}
+
+	// the UrlToObj form is accepted by ObjToUrl
+	obj["transformations"] = transformation.Maps()
+	obj["options"] = map[string]string{"dpr": "2.0", "f_auto": "true"}
+	generated, err := url.ObjToUrl(obj)
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if generated != "https://cdn.pixelbin.io/v2/red-scene-95b6ea/t.resize(h:200,w:300)~t.flip()~p:preset1(a:12)/image.jpeg?dpr=2.0&f_auto=true" {
+		t.Errorf("Failed ! unexpected url %s", generated)
+	}
+
+	parsed, err := url.ParsePattern(expected.Pattern())
+	if err != nil || !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("Failed ! expected %+v, got %+v, err %v", expected, parsed, err)
+	}
+	for _, invalid := range []interface{}{"t.flip()", []map[string]interface{}{{"name": "flip", "values": 1}}} {
+		if _, err := url.TransformationFromMaps(invalid); err == nil {
+			t.Errorf("Failed ! expected an error for %v", invalid)
+		}
+	}
+	if _, err := url.ParsePattern("flip"); err == nil {
+		t.Errorf("Failed ! expected an error for an invalid pattern")
+	}
+}
+
+func TestTransformationRoundTrip(t *testing.T) {
+	transformation := url.NewTransformation().
+		Resize(url.ResizeOptions{Width: 300, Height: 200, Fit: "contain", Background: "ffffff", Position: "top"}).
+		Compress(80).
+		Blur(0.5).
+		ToFormat("webp").
+		Op("erase", "bg", url.P("i", "general"), url.P("shadow", true)).
+		Preset("thumbnail", url.P("h", 80), url.P("w", 120))
+
+	parsed, err := url.ParsePattern(transformation.Pattern())
+	if err != nil || !reflect.DeepEqual(parsed, transformation) {
+		t.Errorf("Failed ! expected %+v, got %+v, err %v", transformation, parsed, err)
+	}
+	obj, err := url.UrlToObj("https://cdn.pixelbin.io/v2/red-scene-95b6ea/" + transformation.Pattern() + "/image.jpeg")
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	fromMaps, err := url.TransformationFromMaps(obj["transformations"])
+	if err != nil || !reflect.DeepEqual(fromMaps, transformation) {
+		t.Errorf("Failed ! expected %+v, got %+v, err %v", transformation, fromMaps, err)
+	}
+}
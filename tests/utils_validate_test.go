@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pixelbin-dev/pixelbin-go/v2/sdk/utils/url"
+)
+
+func TestValidatePattern(t *testing.T) {
+	catalogue := loadCatalogue(t)
+
+	diagnostics, err := catalogue.ValidatePattern("t.resize(h:200,w:300,f:cover,b:ff00aa)~erase.bg(i:ecommerce)~t.blur(s:2.5)~p:preset1(w:abc)")
+	if err != nil || len(diagnostics) != 0 {
+		t.Errorf("Failed ! expected a valid pattern, got %v, err %v", diagnostics, err)
+	}
+
+	diagnostics, err = catalogue.ValidatePattern("t.resize(w:abc,h:20000,f:stretch,b:red)~t.flop()~x.y()~t.compress(z:1)~wm.remove(rem_text:yes)~t.toFormat(p:true)")
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	type found struct {
+		index int
+		param string
+		code  string
+	}
+	got := []found{}
+	for _, d := range diagnostics {
+		got = append(got, found{d.Index, d.Param, d.Code})
+	}
+	// params are sorted by key when a pattern is parsed
+	expected := []found{
+		{0, "b", url.DiagnosticInvalidType},
+		{0, "f", url.DiagnosticInvalidEnum},
+		{0, "h", url.DiagnosticOutOfRange},
+		{0, "w", url.DiagnosticInvalidType},
+		{1, "", url.DiagnosticUnknownOperation},
+		{2, "", url.DiagnosticUnknownPlugin},
+		{3, "z", url.DiagnosticUnknownParam},
+		{4, "", url.DiagnosticDisabledPlugin},
+		{4, "rem_text", url.DiagnosticInvalidType},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Failed ! expected %v, got %v", expected, got)
+	}
+	if message := diagnostics[3].String(); message != `operation 0 t.resize(b:red,f:stretch,h:20000,w:abc): w: expected an integer, got "abc"` {
+		t.Errorf("Failed ! unexpected message %s", message)
+	}
+
+	if _, err := catalogue.ValidatePattern("flip"); err == nil {
+		t.Errorf("Failed ! expected an error for an invalid pattern")
+	}
+}
+
+func TestValidateUrl(t *testing.T) {
+	catalogue := loadCatalogue(t)
+
+	diagnostics, err := catalogue.ValidateUrl("https://cdn.pixelbin.io/v2/red-scene-95b6ea/z-slug/t.resize(w:abc)~t.flip()/image.jpeg?dpr=2.0")
+	if err != nil {
+		t.Fatalf("Failed ! got err %v", err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Index != 0 || diagnostics[0].Code != url.DiagnosticInvalidType {
+		t.Errorf("Failed ! unexpected diagnostics %v", diagnostics)
+	}
+
+	diagnostics, err = catalogue.ValidateUrl("https://assets.example.com/v2/t.compress(q:0)/image.jpeg", url.WithCustomDomain(true))
+	if err != nil || len(diagnostics) != 1 || diagnostics[0].Code != url.DiagnosticOutOfRange {
+		t.Errorf("Failed ! unexpected diagnostics %v, err %v", diagnostics, err)
+	}
+
+	diagnostics, err = catalogue.ValidateUrl("https://cdn.pixelbin.io/v2/red-scene-95b6ea/original/image.jpeg")
+	if err != nil || len(diagnostics) != 0 {
+		t.Errorf("Failed ! unexpected diagnostics %v, err %v", diagnostics, err)
+	}
+
+	if _, err := catalogue.ValidateUrl("https://cdn.pixelbin.io/red-scene-95b6ea"); err == nil {
+		t.Errorf("Failed ! expected an error for an invalid url")
+	}
+}